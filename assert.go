@@ -0,0 +1,332 @@
+package fixture
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/iFaceless/fixture/exporters"
+)
+
+// envUpdateGolden makes Scope.AssertTables rewrite the golden files, it's
+// read at every call rather than registered as a flag, which would show up
+// in every binary importing the package.
+const envUpdateGolden = "FIXTURE_UPDATE"
+
+func updateGolden() bool {
+	update, _ := strconv.ParseBool(os.Getenv(envUpdateGolden))
+	return update
+}
+
+// AssertTables compares the current data of the selected tables with the
+// expected fixture files in dir (e.g. 'testdata/expected/user.yml'), which
+// are written in any format whose loader is a ContentLoader. Only the
+// columns present in the expected rows are compared, and rows are matched
+// by primary key if they have one. It reports a row/column diff to t and
+// returns whether all tables match.
+//
+// Run the tests with FIXTURE_UPDATE=1 to rewrite the golden files from the
+// current data through the exporters instead, existing files keep their
+// columns and new files are written as YAML with all the columns.
+func (s *Scope) AssertTables(t testing.TB, dir string) bool {
+	t.Helper()
+
+	ok := true
+	for _, tb := range s.selectedTables {
+		columns, rawRows, err := s.dumpTable(context.Background(), tb)
+		if err != nil {
+			t.Errorf("%s", err)
+			ok = false
+			continue
+		}
+
+		golden := searchFixtureData(dir, tb.name, "")
+		if updateGolden() {
+			if err := writeGolden(dir, tb, golden, columns, rawRows); err != nil {
+				t.Errorf("fixture: failed to update golden file of table '%s': %s", tb.name, err)
+				ok = false
+			}
+			continue
+		}
+
+		if golden == nil {
+			t.Errorf("fixture: golden file not found for table '%s' in '%s'", tb.name, dir)
+			ok = false
+			continue
+		}
+
+		expected, err := parseFixtureData(golden)
+		if err != nil {
+			t.Errorf("fixture: %s", err)
+			ok = false
+			continue
+		}
+
+		actual := exporters.NewExportContent(tb.name, columns, rawRows)
+		if diffs := diffRows(tb, expected, actual); len(diffs) > 0 {
+			t.Errorf("fixture: table '%s' does not match '%s':\n  %s", tb.name, golden.Path, strings.Join(diffs, "\n  "))
+			ok = false
+		}
+	}
+	return ok
+}
+
+// dumpTable queries all rows of a table ordered by primary key, in the
+// raw form taken by the exporters.
+func (s *Scope) dumpTable(ctx context.Context, tb *table) ([]string, [][][]byte, error) {
+	query := "SELECT * FROM " + tb.sqlName
+	if len(tb.primaryKey) > 0 {
+		columns := make([]string, len(tb.primaryKey))
+		for i, col := range tb.primaryKey {
+			columns[i] = quoteName(col)
+		}
+		query += " ORDER BY " + strings.Join(columns, ", ")
+	}
+
+	ctx, cancel := s.tf.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.DB().QueryContext(ctx, query)
+	if err != nil {
+		return nil, nil, &OpError{Op: "query", Table: tb.name, Statement: query, Err: err}
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, nil, &OpError{Op: "query", Table: tb.name, Statement: query, Err: err}
+	}
+
+	rawRows := make([][][]byte, 0)
+	for rows.Next() {
+		columnValues := make([][]byte, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range columnValues {
+			dest[i] = &columnValues[i]
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, nil, &OpError{Op: "query", Table: tb.name, Statement: query, Err: err}
+		}
+		rawRows = append(rawRows, columnValues)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, &OpError{Op: "query", Table: tb.name, Statement: query, Err: err}
+	}
+	return columns, rawRows, nil
+}
+
+// parseFixtureData reads the rows of a fixture file through its loader.
+func parseFixtureData(data *fixtureData) ([]map[string]interface{}, error) {
+	loader, ok := LookupLoader(data.Format).(ContentLoader)
+	if !ok {
+		return nil, fmt.Errorf("cannot read rows from '%s': format '%s' is not supported", data.Path, data.Format)
+	}
+
+	content, err := loader.Parse(data.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]map[string]interface{}, 0, len(content.Rows))
+	for _, row := range content.Rows {
+		rows = append(rows, copyRow(row))
+	}
+	return rows, nil
+}
+
+// writeGolden writes the rows to the golden file of a table. An existing
+// golden file keeps its columns, so the ones left out on purpose, such as
+// timestamps, aren't written back.
+func writeGolden(dir string, tb *table, golden *fixtureData, columns []string, rawRows [][][]byte) error {
+	if golden == nil {
+		golden = &fixtureData{Path: path.Join(dir, tb.name+".yml"), Format: YAML}
+	} else {
+		expected, err := parseFixtureData(golden)
+		if err != nil {
+			return err
+		}
+		columns, rawRows = selectColumns(expected, columns, rawRows)
+	}
+
+	output, err := LookupExporter(golden.Format).Export(tb.name, columns, rawRows)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(golden.Path, output, 0644)
+}
+
+// selectColumns keeps the columns present in the expected rows, or all of
+// them if there are no expected rows.
+func selectColumns(expected []map[string]interface{}, columns []string, rawRows [][][]byte) ([]string, [][][]byte) {
+	if len(expected) == 0 {
+		return columns, rawRows
+	}
+
+	var indexes []int
+	for i, col := range columns {
+		for _, row := range expected {
+			if _, ok := row[col]; ok {
+				indexes = append(indexes, i)
+				break
+			}
+		}
+	}
+
+	selected := make([]string, len(indexes))
+	for i, index := range indexes {
+		selected[i] = columns[index]
+	}
+
+	selectedRows := make([][][]byte, len(rawRows))
+	for i, row := range rawRows {
+		selectedRows[i] = make([][]byte, len(indexes))
+		for j, index := range indexes {
+			selectedRows[i][j] = row[index]
+		}
+	}
+	return selected, selectedRows
+}
+
+// diffRows describes the differences between the expected rows and the
+// actual ones, it's empty if they match.
+func diffRows(tb *table, expected []map[string]interface{}, actual *exporters.ExportContent) []string {
+	diffs := make([]string, 0)
+	if len(expected) != len(actual.Rows) {
+		diffs = append(diffs, fmt.Sprintf("row count: expected %d, got %d", len(expected), len(actual.Rows)))
+	}
+
+	if byKey := canMatchByKey(tb, expected); byKey {
+		actualByKey := make(map[string]*exporters.SortedMap)
+		for _, row := range actual.Rows {
+			actualByKey[rowKey(tb, row.Get)] = row
+		}
+
+		seen := make(map[string]bool)
+		for _, want := range expected {
+			key := rowKey(tb, func(col string) (interface{}, bool) {
+				v, ok := want[col]
+				return normalizeValue(v), ok
+			})
+			seen[key] = true
+
+			got, ok := actualByKey[key]
+			if !ok {
+				diffs = append(diffs, fmt.Sprintf("row %s: missing", key))
+				continue
+			}
+			diffs = append(diffs, diffRow("row "+key, want, got)...)
+		}
+
+		for _, row := range actual.Rows {
+			if key := rowKey(tb, row.Get); !seen[key] {
+				diffs = append(diffs, fmt.Sprintf("row %s: unexpected %s", key, formatRow(row)))
+			}
+		}
+		return diffs
+	}
+
+	for i, want := range expected {
+		if i >= len(actual.Rows) {
+			diffs = append(diffs, fmt.Sprintf("row #%d: missing", i+1))
+			continue
+		}
+		diffs = append(diffs, diffRow(fmt.Sprintf("row #%d", i+1), want, actual.Rows[i])...)
+	}
+	for i := len(expected); i < len(actual.Rows); i++ {
+		diffs = append(diffs, fmt.Sprintf("row #%d: unexpected %s", i+1, formatRow(actual.Rows[i])))
+	}
+	return diffs
+}
+
+func canMatchByKey(tb *table, expected []map[string]interface{}) bool {
+	if len(tb.primaryKey) == 0 {
+		return false
+	}
+
+	for _, row := range expected {
+		for _, col := range tb.primaryKey {
+			if _, ok := row[col]; !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// rowKey renders the primary key of a row, e.g. 'id=1'.
+func rowKey(tb *table, get func(col string) (interface{}, bool)) string {
+	parts := make([]string, len(tb.primaryKey))
+	for i, col := range tb.primaryKey {
+		v, _ := get(col)
+		parts[i] = fmt.Sprintf("%s=%v", col, v)
+	}
+	return strings.Join(parts, ",")
+}
+
+func diffRow(label string, want map[string]interface{}, got *exporters.SortedMap) []string {
+	columns := make([]string, 0, len(want))
+	for col := range want {
+		columns = append(columns, col)
+	}
+	sort.Strings(columns)
+
+	diffs := make([]string, 0)
+	for _, col := range columns {
+		gotVal, ok := got.Get(col)
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("%s: column '%s' not found", label, col))
+			continue
+		}
+
+		wantVal := normalizeValue(want[col])
+		if wantVal != gotVal {
+			diffs = append(diffs, fmt.Sprintf("%s: column '%s': expected %s, got %s",
+				label, col, formatValue(wantVal), formatValue(gotVal)))
+		}
+	}
+	return diffs
+}
+
+// normalizeValue renders an expected value the way the exporters render
+// database values, a string or nil.
+func normalizeValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case bool:
+		if val {
+			return "1"
+		}
+		return "0"
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func formatValue(v interface{}) string {
+	if v == nil {
+		return "NULL"
+	}
+	return fmt.Sprintf("%q", v)
+}
+
+func formatRow(row *exporters.SortedMap) string {
+	fields := make([]string, 0)
+	for _, col := range row.Keys() {
+		v, _ := row.Get(col)
+		fields = append(fields, fmt.Sprintf("%s: %s", col, formatValue(v)))
+	}
+	return "{" + strings.Join(fields, ", ") + "}"
+}
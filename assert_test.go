@@ -0,0 +1,110 @@
+package fixture
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/iFaceless/fixture/exporters"
+	"github.com/stretchr/testify/assert"
+)
+
+var expectedDataDir = path.Join(testDataDir, "expected")
+
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (tb *recordingTB) Helper() {}
+
+func (tb *recordingTB) Errorf(format string, args ...interface{}) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+func Test_diffRows(t *testing.T) {
+	tb := &table{name: "user", primaryKey: []string{"id"}}
+	actual := exporters.NewExportContent("user", []string{"id", "name", "deleted_at"}, [][][]byte{
+		{[]byte("1"), []byte("Kary"), nil},
+		{[]byte("3"), []byte("Tom"), nil},
+	})
+
+	expected := []map[string]interface{}{
+		{"id": 1, "name": "Kary", "deleted_at": nil},
+		{"id": 3, "name": "Tom"},
+	}
+	assert.Empty(t, diffRows(tb, expected, actual))
+
+	expected = []map[string]interface{}{
+		{"id": 1, "name": "Jack", "deleted_at": "2019-01-01"},
+		{"id": 2, "name": "Tom"},
+	}
+	assert.Equal(t, []string{
+		`row id=1: column 'deleted_at': expected "2019-01-01", got NULL`,
+		`row id=1: column 'name': expected "Jack", got "Kary"`,
+		`row id=2: missing`,
+		`row id=3: unexpected {id: "3", name: "Tom", deleted_at: NULL}`,
+	}, diffRows(tb, expected, actual))
+
+	// Rows without primary key are compared in order
+	expected = []map[string]interface{}{{"name": "Kary"}}
+	assert.Equal(t, []string{
+		`row count: expected 1, got 2`,
+		`row #2: unexpected {id: "3", name: "Tom", deleted_at: NULL}`,
+	}, diffRows(tb, expected, actual))
+}
+
+func (s *SuiteTestFixtureTester) TestAssertTables() {
+	scope := s.tf.Use("user")
+	defer scope.Clear()
+
+	assert.True(s.T(), scope.AssertTables(s.T(), expectedDataDir))
+
+	s.db.Exec("UPDATE user SET address = 'Hangzhou, China' WHERE id = 2")
+	tb := &recordingTB{TB: s.T()}
+	assert.False(s.T(), scope.AssertTables(tb, expectedDataDir))
+	if assert.Equal(s.T(), 1, len(tb.errors)) {
+		assert.Contains(s.T(), tb.errors[0], `row id=2: column 'address': expected "Shanghai, China", got "Hangzhou, China"`)
+	}
+}
+
+func (s *SuiteTestFixtureTester) TestAssertTables_Update() {
+	dir := tempDir(s.T())
+	scope := s.tf.Use("user")
+	defer scope.Clear()
+
+	os.Setenv(envUpdateGolden, "1")
+	defer os.Unsetenv(envUpdateGolden)
+	assert.True(s.T(), scope.AssertTables(s.T(), dir))
+	assert.True(s.T(), isPathExist(path.Join(dir, "user.yml")))
+
+	os.Unsetenv(envUpdateGolden)
+	assert.True(s.T(), scope.AssertTables(s.T(), dir))
+}
+
+func (s *SuiteTestFixtureTester) TestAssertTables_UpdateKeepsColumns() {
+	dir := tempDir(s.T())
+	content, err := ioutil.ReadFile(path.Join(expectedDataDir, "user.yml"))
+	assert.Nil(s.T(), err)
+	assert.Nil(s.T(), ioutil.WriteFile(path.Join(dir, "user.yml"), content, 0644))
+
+	scope := s.tf.Use("user")
+	defer scope.Clear()
+
+	os.Setenv(envUpdateGolden, "1")
+	defer os.Unsetenv(envUpdateGolden)
+	s.db.Exec("UPDATE user SET address = 'Hangzhou, China' WHERE id = 2")
+	assert.True(s.T(), scope.AssertTables(s.T(), dir))
+
+	content, err = ioutil.ReadFile(path.Join(dir, "user.yml"))
+	assert.Nil(s.T(), err)
+	assert.Contains(s.T(), string(content), "Hangzhou, China")
+	assert.NotContains(s.T(), string(content), "created_at")
+
+	// Timestamps left out of the golden file don't matter
+	os.Unsetenv(envUpdateGolden)
+	s.db.Exec("UPDATE user SET created_at = '2001-01-01 00:00:00', updated_at = '2001-01-01 00:00:00'")
+	assert.True(s.T(), scope.AssertTables(s.T(), dir))
+}
@@ -0,0 +1,284 @@
+package fixture
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// ClearStrategy decides how a table is cleared when a scope is cleared.
+type ClearStrategy int
+
+const (
+	// ClearTruncate runs 'TRUNCATE TABLE', which is the default.
+	ClearTruncate ClearStrategy = iota
+	// ClearDelete runs 'DELETE FROM', which works under foreign keys.
+	ClearDelete
+	// ClearDeleteResetAutoIncrement runs 'DELETE FROM' and resets the
+	// AUTO_INCREMENT counter to 1.
+	ClearDeleteResetAutoIncrement
+	// ClearRecreate drops the table and creates it again from the schema.
+	ClearRecreate
+	// ClearInserted only deletes the rows inserted by the scope, tracked
+	// by primary key. It must be configured on the TestFixture, so rows
+	// are tracked while loading.
+	ClearInserted
+)
+
+func (cs ClearStrategy) String() string {
+	switch cs {
+	case ClearTruncate:
+		return "truncate"
+	case ClearDelete:
+		return "delete"
+	case ClearDeleteResetAutoIncrement:
+		return "delete-reset-auto-increment"
+	case ClearRecreate:
+		return "recreate"
+	case ClearInserted:
+		return "inserted"
+	default:
+		return fmt.Sprintf("ClearStrategy(%d)", int(cs))
+	}
+}
+
+// ClearWith overrides the clear strategy of the scope for all its tables.
+func (s *Scope) ClearWith(strategy ClearStrategy) *Scope {
+	return s.ClearTableWith("", strategy)
+}
+
+// ClearTableWith overrides the clear strategy of the scope for a table.
+// ClearInserted panics for the tables loaded already without tracking
+// their rows, set it on the TestFixture for them instead.
+func (s *Scope) ClearTableWith(tableName string, strategy ClearStrategy) *Scope {
+	if strategy == ClearInserted {
+		s.checkTracked(tableName)
+	}
+
+	if s.clearStrategies == nil {
+		s.clearStrategies = make(map[string]ClearStrategy)
+	}
+	s.clearStrategies[tableName] = strategy
+	return s
+}
+
+// checkTracked makes sure the rows inserted into the table, or all tables
+// if the name is empty, are tracked for ClearInserted. Tables of a
+// transaction are rolled back instead, so they aren't checked.
+func (s *Scope) checkTracked(tableName string) {
+	if s.tx != nil || s.tf.config.DryRun != nil {
+		return
+	}
+
+	for _, tb := range s.selectedTables {
+		if tableName != "" && tb.name != tableName {
+			continue
+		}
+		if _, ok := s.insertedKeys[tb.name]; !ok {
+			panic(fmt.Sprintf("rows of table '%s' are not tracked, set ClearInserted on the TestFixture instead of the scope", tb.name))
+		}
+	}
+}
+
+// clearStrategy resolves the strategy of a table, the overrides of the
+// scope come before the ones of the fixture and per table overrides come
+// before the defaults.
+func (s *Scope) clearStrategy(tb *table) ClearStrategy {
+	if strategy, ok := s.clearStrategies[tb.name]; ok {
+		return strategy
+	}
+	if strategy, ok := s.clearStrategies[""]; ok {
+		return strategy
+	}
+	return s.tf.clearStrategy(tb)
+}
+
+func (tf *TestFixture) clearStrategy(tb *table) ClearStrategy {
+	if strategy, ok := tf.config.TableClearStrategies[tb.name]; ok {
+		return strategy
+	}
+	return tf.config.ClearStrategy
+}
+
+// clearStatements returns the statements clearing a table, rows inserted
+// by a scope are cleared by clearInserted instead, whose statements are
+// rendered by clearInsertedStatements.
+func clearStatements(tb *table, strategy ClearStrategy) []string {
+	switch strategy {
+	case ClearDelete, ClearInserted:
+		return []string{"DELETE FROM " + tb.sqlName}
+	case ClearDeleteResetAutoIncrement:
+		return []string{
+			"DELETE FROM " + tb.sqlName,
+			"ALTER TABLE " + tb.sqlName + " AUTO_INCREMENT = 1",
+		}
+	case ClearRecreate:
+		return []string{"DROP TABLE " + tb.sqlName, tb.createSQL}
+	default:
+		return []string{"TRUNCATE TABLE " + tb.sqlName}
+	}
+}
+
+func (tf *TestFixture) clearTable(ctx context.Context, db DBTX, tb *table, strategy ClearStrategy) error {
+	if err := tf.checkRowLimit(ctx, db, tb); err != nil {
+		return err
+	}
+
+	for _, stmt := range clearStatements(tb, strategy) {
+		if _, err := tf.exec(ctx, db, "clear", tb.name, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Scope) clearInserted(ctx context.Context, db DBTX, tb *table) error {
+	keys, ok := s.insertedKeys[tb.name]
+	if !ok {
+		return &OpError{
+			Op:    "clear",
+			Table: tb.name,
+			Err:   fmt.Errorf("inserted rows are not tracked, set ClearInserted on the TestFixture instead of the scope"),
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	query, args := deleteKeysSQL(tb, keys)
+	_, err := s.tf.exec(ctx, db, "clear", tb.name, query, args...)
+	return err
+}
+
+// clearInsertedStatements renders the clearing of the rows a scope inserted
+// into a table, with a placeholder for the keys until they're tracked.
+func (s *Scope) clearInsertedStatements(tb *table) []string {
+	keys, ok := s.insertedKeys[tb.name]
+	if !ok {
+		return []string{fmt.Sprintf("DELETE FROM %s WHERE (%s) IN (/* keys of the inserted rows */)",
+			tb.sqlName, strings.Join(keyColumns(tb), ", "))}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	tuples := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values := make([]string, len(key))
+		for i, val := range key {
+			values[i] = quoteLiteral(val)
+		}
+		tuples = append(tuples, "("+strings.Join(values, ", ")+")")
+	}
+	return []string{fmt.Sprintf("DELETE FROM %s WHERE (%s) IN (%s)",
+		tb.sqlName, strings.Join(keyColumns(tb), ", "), strings.Join(tuples, ", "))}
+}
+
+// deleteKeysSQL deletes rows by primary key, e.g.
+// 'DELETE FROM t WHERE (`a`, `b`) IN ((?, ?), (?, ?))'.
+func deleteKeysSQL(tb *table, keys [][]string) (string, []interface{}) {
+	columns := keyColumns(tb)
+	placeholder := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+
+	tuples := make([]string, 0, len(keys))
+	args := make([]interface{}, 0, len(keys)*len(columns))
+	for _, key := range keys {
+		tuples = append(tuples, placeholder)
+		for _, val := range key {
+			args = append(args, val)
+		}
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE (%s) IN (%s)",
+		tb.sqlName, strings.Join(columns, ", "), strings.Join(tuples, ", "))
+	return query, args
+}
+
+// keyColumns quotes the columns of the primary key of a table.
+func keyColumns(tb *table) []string {
+	columns := make([]string, len(tb.primaryKey))
+	for i, col := range tb.primaryKey {
+		columns[i] = quoteName(col)
+	}
+	return columns
+}
+
+// trackInserted runs insert and records the primary keys it adds to the
+// table, by comparing the keys before and after.
+func (s *Scope) trackInserted(ctx context.Context, db DBTX, tb *table, insert func() error) error {
+	if len(tb.primaryKey) == 0 {
+		return &OpError{Op: "track", Table: tb.name, Err: fmt.Errorf("no primary key to track inserted rows")}
+	}
+
+	before, err := s.queryKeys(ctx, db, tb)
+	if err != nil {
+		return err
+	}
+
+	if err := insert(); err != nil {
+		return err
+	}
+
+	after, err := s.queryKeys(ctx, db, tb)
+	if err != nil {
+		return err
+	}
+
+	if s.insertedKeys == nil {
+		s.insertedKeys = make(map[string][][]string)
+	}
+
+	existing := make(map[string]bool, len(before))
+	for _, key := range before {
+		existing[strings.Join(key, "\x00")] = true
+	}
+
+	inserted := s.insertedKeys[tb.name]
+	if inserted == nil {
+		inserted = make([][]string, 0)
+	}
+	for _, key := range after {
+		if !existing[strings.Join(key, "\x00")] {
+			inserted = append(inserted, key)
+		}
+	}
+	s.insertedKeys[tb.name] = inserted
+	return nil
+}
+
+func (s *Scope) queryKeys(ctx context.Context, db DBTX, tb *table) ([][]string, error) {
+	columns := make([]string, len(tb.primaryKey))
+	for i, col := range tb.primaryKey {
+		columns[i] = quoteName(col)
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(columns, ", "), tb.sqlName)
+
+	ctx, cancel := s.tf.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, &OpError{Op: "track", Table: tb.name, Statement: query, Err: err}
+	}
+	defer rows.Close()
+
+	keys := make([][]string, 0)
+	for rows.Next() {
+		values := make([]sql.RawBytes, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, &OpError{Op: "track", Table: tb.name, Statement: query, Err: err}
+		}
+
+		key := make([]string, len(values))
+		for i, val := range values {
+			key[i] = string(val)
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
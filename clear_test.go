@@ -0,0 +1,81 @@
+package fixture
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_clearStatements(t *testing.T) {
+	tb := &table{name: "user", sqlName: "user", createSQL: "CREATE TABLE user (id int)"}
+
+	assert.Equal(t, []string{"TRUNCATE TABLE user"}, clearStatements(tb, ClearTruncate))
+	assert.Equal(t, []string{"DELETE FROM user"}, clearStatements(tb, ClearDelete))
+	assert.Equal(t, []string{"DELETE FROM user", "ALTER TABLE user AUTO_INCREMENT = 1"}, clearStatements(tb, ClearDeleteResetAutoIncrement))
+	assert.Equal(t, []string{"DROP TABLE user", "CREATE TABLE user (id int)"}, clearStatements(tb, ClearRecreate))
+}
+
+func Test_deleteKeysSQL(t *testing.T) {
+	tb := &table{name: "user", sqlName: "user", primaryKey: []string{"id"}}
+	query, args := deleteKeysSQL(tb, [][]string{{"1"}, {"2"}})
+	assert.Equal(t, "DELETE FROM user WHERE (`id`) IN ((?), (?))", query)
+	assert.Equal(t, []interface{}{"1", "2"}, args)
+
+	tb = &table{name: "member", sqlName: "member", primaryKey: []string{"group_id", "user_id"}}
+	query, args = deleteKeysSQL(tb, [][]string{{"1", "2"}})
+	assert.Equal(t, "DELETE FROM member WHERE (`group_id`, `user_id`) IN ((?, ?))", query)
+	assert.Equal(t, []interface{}{"1", "2"}, args)
+}
+
+func Test_clearStrategy(t *testing.T) {
+	tf := &TestFixture{config: new(Config)}
+	user, task := &table{name: "user"}, &table{name: "task"}
+	scope := &Scope{tf: tf}
+	assert.Equal(t, ClearTruncate, scope.clearStrategy(user))
+
+	ClearWith(ClearDelete)(tf)
+	ClearTableWith("task", ClearRecreate)(tf)
+	assert.Equal(t, ClearDelete, scope.clearStrategy(user))
+	assert.Equal(t, ClearRecreate, scope.clearStrategy(task))
+
+	scope.ClearWith(ClearDeleteResetAutoIncrement)
+	assert.Equal(t, ClearDeleteResetAutoIncrement, scope.clearStrategy(user))
+	assert.Equal(t, ClearDeleteResetAutoIncrement, scope.clearStrategy(task))
+
+	scope.ClearTableWith("user", ClearTruncate)
+	assert.Equal(t, ClearTruncate, scope.clearStrategy(user))
+	assert.Equal(t, "delete-reset-auto-increment", ClearDeleteResetAutoIncrement.String())
+}
+
+func (s *SuiteTestFixtureTester) TestClear_WithStrategies() {
+	for _, strategy := range []ClearStrategy{ClearDelete, ClearDeleteResetAutoIncrement, ClearRecreate} {
+		scope := s.tf.Use("user").ClearWith(strategy)
+		assert.Equal(s.T(), 2, countTable(s.db, "user"))
+		scope.Clear()
+		assert.Equal(s.T(), 0, countTable(s.db, "user"), strategy.String())
+	}
+}
+
+func (s *SuiteTestFixtureTester) TestClear_InsertedRows() {
+	tf := s.newFixture(ClearTableWith("user", ClearInserted))
+
+	_, err := s.db.Exec("INSERT INTO user (id, nickname) VALUES (100, 1)")
+	assert.Nil(s.T(), err)
+	defer s.db.Exec("TRUNCATE TABLE user")
+
+	scope := tf.Use("user", "foo")
+	assert.Equal(s.T(), [][]string{{"1"}, {"2"}}, scope.insertedKeys["user"])
+	assert.Equal(s.T(), 3, countTable(s.db, "user"))
+
+	scope.Clear()
+	assert.Equal(s.T(), 1, countTable(s.db, "user"))
+	assert.Equal(s.T(), 0, countTable(s.db, "foo"))
+
+	// Rows are not tracked if the strategy is only set on the scope
+	scope = s.tf.Use("foo")
+	assert.PanicsWithValue(s.T(), "rows of table 'foo' are not tracked, set ClearInserted on the TestFixture instead of the scope", func() {
+		scope.ClearWith(ClearInserted)
+	})
+	scope.Clear()
+	assert.Equal(s.T(), 0, countTable(s.db, "foo"))
+}
@@ -1,19 +1,51 @@
 package fixture
 
 import (
+	"database/sql"
 	"fmt"
-	"log"
-	"strings"
+	"io"
+	"time"
 )
 
 type Config struct {
-	DatabaseURL    *DatabaseURL
-	FixtureDataDir string
-	SchemaFilepath string
+	DatabaseURL     *DatabaseURL
+	DB              *sql.DB
+	FixtureDataDir  string
+	SchemaFilepath  string
+	Isolation       IsolationMode
+	PoolSize        int
+	PoolTimeout     time.Duration
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	Timeout         time.Duration
+	ReadyTimeout    time.Duration
+	Logger          Logger
+	Safety          *SafetyConfig
+	ProcessLock     bool
+	LockTimeout     time.Duration
+	TableLocks      bool
+	TablePrefix     string
+	// CreateDatabase creates the test database with Charset and Collation
+	// if it's missing, and DropDatabase drops the databases created by the
+	// fixture at TestFixture.Close.
+	CreateDatabase bool
+	Charset        string
+	Collation      string
+	DropDatabase   bool
+	// ClearStrategy is how tables are cleared by default, and
+	// TableClearStrategies overrides it per table.
+	ClearStrategy        ClearStrategy
+	TableClearStrategies map[string]ClearStrategy
+	// ZeroValues is how Scope.InsertStructs handles fields of zero value.
+	ZeroValues ZeroValuePolicy
+	// DryRun receives the statements in place of the database.
+	DryRun io.Writer
 }
 
 func (c *Config) Validate() error {
-	if c.DatabaseURL == nil {
+	// clones of the pool can only be reached by url
+	if c.DryRun == nil && c.DatabaseURL == nil && (c.DB == nil || c.PoolSize > 0) {
 		return ErrMissingDBRawURL
 	}
 
@@ -42,6 +74,148 @@ func DataDir(dir string) Option {
 	}
 }
 
+// Isolation sets how scopes isolate their fixture data, defaults to
+// TruncateIsolation.
+func Isolation(mode IsolationMode) Option {
+	return func(tf *TestFixture) {
+		tf.config.Isolation = mode
+	}
+}
+
+// Pool makes the TestFixture create size clones of the test database
+// (e.g. 'test_foo_1' ... 'test_foo_N'), every scope checks out a free
+// clone and waits at most timeout for one, a non-positive timeout
+// waits forever.
+func Pool(size int, timeout time.Duration) Option {
+	return func(tf *TestFixture) {
+		tf.config.PoolSize = size
+		tf.config.PoolTimeout = timeout
+	}
+}
+
+// SharedDB makes the fixture run on an existing handle instead of opening
+// its own, so the fixture and code under test can share one pool or one
+// registered driver wrapper. The handle must connect to a 'test_' database
+// and it's not closed by TestFixture.Close.
+func SharedDB(db *sql.DB) Option {
+	return func(tf *TestFixture) {
+		tf.config.DB = db
+	}
+}
+
+// MaxOpenConns limits the open connections of every database handle
+// opened by the fixture, see sql.DB.SetMaxOpenConns.
+func MaxOpenConns(n int) Option {
+	return func(tf *TestFixture) {
+		tf.config.MaxOpenConns = n
+	}
+}
+
+// MaxIdleConns limits the idle connections of every database handle
+// opened by the fixture, see sql.DB.SetMaxIdleConns.
+func MaxIdleConns(n int) Option {
+	return func(tf *TestFixture) {
+		tf.config.MaxIdleConns = n
+	}
+}
+
+// ConnMaxLifetime limits how long a connection of the database handles
+// opened by the fixture may be reused, see sql.DB.SetConnMaxLifetime.
+func ConnMaxLifetime(d time.Duration) Option {
+	return func(tf *TestFixture) {
+		tf.config.ConnMaxLifetime = d
+	}
+}
+
+// Timeout bounds every statement the fixture runs, a statement blocked
+// by a lock fails with an *OpError naming the table and the statement
+// instead of hanging the test binary.
+func Timeout(d time.Duration) Option {
+	return func(tf *TestFixture) {
+		tf.config.Timeout = d
+	}
+}
+
+// ReadyTimeout makes New wait at most d for the database server to accept
+// connections, it's pinged with an exponential backoff meanwhile. This
+// helps when the server is still starting, as in docker-compose CI.
+func ReadyTimeout(d time.Duration) Option {
+	return func(tf *TestFixture) {
+		tf.config.ReadyTimeout = d
+	}
+}
+
+// CreateDatabase makes New create the test database if it's missing, with
+// the charset and collation if they're not empty. Like the clones of the
+// pool, only a 'test_' database is created.
+func CreateDatabase(charset, collation string) Option {
+	return func(tf *TestFixture) {
+		for _, name := range []string{charset, collation} {
+			if name != "" && !charsetRule.MatchString(name) {
+				panic(fmt.Sprintf("invalid charset or collation '%s'", name))
+			}
+		}
+
+		tf.config.CreateDatabase = true
+		tf.config.Charset = charset
+		tf.config.Collation = collation
+	}
+}
+
+// DropDatabaseOnClose makes TestFixture.Close drop the databases created
+// by the fixture, the test database of the CreateDatabase option and the
+// clones of the pool.
+func DropDatabaseOnClose() Option {
+	return func(tf *TestFixture) {
+		tf.config.DropDatabase = true
+	}
+}
+
+// ClearWith sets how tables are cleared by default, defaults to
+// ClearTruncate.
+func ClearWith(strategy ClearStrategy) Option {
+	return func(tf *TestFixture) {
+		tf.config.ClearStrategy = strategy
+	}
+}
+
+// ClearTableWith sets how a table is cleared, overriding ClearWith.
+func ClearTableWith(tableName string, strategy ClearStrategy) Option {
+	return func(tf *TestFixture) {
+		if tf.config.TableClearStrategies == nil {
+			tf.config.TableClearStrategies = make(map[string]ClearStrategy)
+		}
+		tf.config.TableClearStrategies[tableName] = strategy
+	}
+}
+
+// ZeroValues sets how Scope.InsertStructs handles fields of zero value,
+// defaults to OmitZeroValues.
+func ZeroValues(policy ZeroValuePolicy) Option {
+	return func(tf *TestFixture) {
+		tf.config.ZeroValues = policy
+	}
+}
+
+// DryRun makes the fixture write the statements it would run to w without
+// connecting to the database, the creation of the tables at New, the
+// inserts of every scope at Use and the clearing of them at Clear. Hooks
+// don't run, queries of scopes aren't supported, and ids created by
+// factories are 0 unless set by the factories.
+func DryRun(w io.Writer) Option {
+	return func(tf *TestFixture) {
+		tf.config.DryRun = w
+	}
+}
+
+// WithLogger redirects the logs of the fixture, use NopLogger to silence
+// them or TestingLogger to keep them in the test log.
+func WithLogger(l Logger) Option {
+	return func(tf *TestFixture) {
+		tf.config.Logger = l
+	}
+}
+
 func Database(rawurl string) Option {
 	return func(tf *TestFixture) {
 		url, err := Parse(rawurl)
@@ -53,11 +227,9 @@ func Database(rawurl string) Option {
 			panic(fmt.Sprintf("db name not found in '%s'", rawurl))
 		}
 
-		if !strings.HasPrefix(dbName, "test_") {
-			panic(fmt.Sprintf("invalid db name '%s': test db name must starts with 'test_'", dbName))
-		}
+		checkTestDBName(dbName)
 
-		log.Printf("config.Database: database config is '%s'", url)
+		tf.logger().Debug("database configured", "url", url.Redacted())
 		tf.config.DatabaseURL = url
 	}
 }
@@ -0,0 +1,120 @@
+package fixture
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v2"
+)
+
+// configFileName is the name of the config file found by FromConfigFile.
+const configFileName = "fixture.yml"
+
+// Environment variables overriding the config file.
+const (
+	envDatabaseURL    = "FIXTURE_DATABASE_URL"
+	envDataDir        = "FIXTURE_DATA_DIR"
+	envSchemaFilepath = "FIXTURE_SCHEMA_FILEPATH"
+)
+
+// fileConfig is the content of the config file, for example:
+//
+//	database: mysql://root@localhost:3306/test_foo?parseTime=true
+//	data_dir: testdata/fixtures
+//	schema: testdata/schema.sql
+type fileConfig struct {
+	Database string `yaml:"database"`
+	DataDir  string `yaml:"data_dir"`
+	Schema   string `yaml:"schema"`
+}
+
+// FromConfigFile configures the database, DataDir and SchemaFilepath from
+// the 'fixture.yml' found in the working directory, which is the package
+// directory under 'go test', or in its parents up to the module root. The
+// environment variables FIXTURE_DATABASE_URL, FIXTURE_DATA_DIR and
+// FIXTURE_SCHEMA_FILEPATH take precedence over the file, and options
+// after FromConfigFile take precedence over both. Relative paths of the
+// file and the environment are relative to the directory of the file, or
+// the module root if there's no file.
+func FromConfigFile() Option {
+	return func(tf *TestFixture) {
+		wd, err := os.Getwd()
+		panicOnErr(err)
+		tf.applyConfigFile(wd)
+	}
+}
+
+func (tf *TestFixture) applyConfigFile(dir string) {
+	filename, baseDir := findConfigFile(dir)
+	conf := fileConfig{}
+	if filename != "" {
+		conf = loadConfigFile(filename)
+		tf.logger().Debug("config file loaded", "path", filename)
+	}
+
+	if v := os.Getenv(envDatabaseURL); v != "" {
+		conf.Database = v
+	}
+	if v := os.Getenv(envDataDir); v != "" {
+		conf.DataDir = v
+	}
+	if v := os.Getenv(envSchemaFilepath); v != "" {
+		conf.Schema = v
+	}
+
+	if conf.Database == "" {
+		panic(fmt.Sprintf("database not configured by '%s' or $%s", configFileName, envDatabaseURL))
+	}
+	Database(conf.Database)(tf)
+
+	// relative paths point to the same place from every package
+	for _, p := range []*string{&conf.DataDir, &conf.Schema} {
+		if *p != "" && !filepath.IsAbs(*p) {
+			*p = filepath.Join(baseDir, *p)
+		}
+	}
+	if conf.DataDir != "" {
+		DataDir(conf.DataDir)(tf)
+	}
+	if conf.Schema != "" {
+		SchemaFilepath(conf.Schema)(tf)
+	}
+}
+
+// findConfigFile walks up from dir to the module root, which contains the
+// 'go.mod', and returns the first config file found or else "". Relative
+// paths are resolved against the base directory, which is the one of the
+// config file, or else the module root, or else dir.
+func findConfigFile(dir string) (filename, baseDir string) {
+	dir, err := filepath.Abs(dir)
+	panicOnErr(err)
+
+	for current := dir; ; {
+		candidate := filepath.Join(current, configFileName)
+		if isPathExist(candidate) {
+			return candidate, current
+		}
+		if isPathExist(filepath.Join(current, "go.mod")) {
+			return "", current
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return "", dir
+		}
+		current = parent
+	}
+}
+
+func loadConfigFile(filename string) fileConfig {
+	buf, err := ioutil.ReadFile(filename)
+	panicOnErr(err)
+
+	conf := fileConfig{}
+	if err := yaml.UnmarshalStrict(buf, &conf); err != nil {
+		panic(fmt.Sprintf("failed to load config file '%s': %s", filename, err))
+	}
+	return conf
+}
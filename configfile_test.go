@@ -0,0 +1,83 @@
+package fixture
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfigFile(t *testing.T, dir, content string) {
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, configFileName), []byte(content), 0644))
+}
+
+func Test_findConfigFile(t *testing.T) {
+	root := tempDir(t)
+	pkgDir := filepath.Join(root, "internal", "user")
+	assert.Nil(t, os.MkdirAll(pkgDir, 0755))
+	filename, baseDir := findConfigFile(pkgDir)
+	assert.Equal(t, "", filename)
+	assert.Equal(t, pkgDir, baseDir)
+
+	writeConfigFile(t, root, "database: mysql://root@localhost/test_foo")
+	filename, baseDir = findConfigFile(pkgDir)
+	assert.Equal(t, filepath.Join(root, configFileName), filename)
+	assert.Equal(t, root, baseDir)
+
+	// the module root stops the walk
+	moduleRoot := filepath.Join(root, "internal")
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(moduleRoot, "go.mod"), nil, 0644))
+	filename, baseDir = findConfigFile(pkgDir)
+	assert.Equal(t, "", filename)
+	assert.Equal(t, moduleRoot, baseDir)
+}
+
+func Test_applyConfigFile(t *testing.T) {
+	root := tempDir(t)
+	pkgDir := filepath.Join(root, "user")
+	assert.Nil(t, os.MkdirAll(pkgDir, 0755))
+	writeConfigFile(t, root, "database: mysql://root@localhost/test_foo\ndata_dir: testdata/fixtures\nschema: /etc/schema.sql\n")
+
+	tf := &TestFixture{config: new(Config)}
+	tf.applyConfigFile(pkgDir)
+	assert.Equal(t, "test_foo", tf.config.DatabaseURL.DBName())
+	assert.Equal(t, filepath.Join(root, "testdata", "fixtures"), tf.config.FixtureDataDir)
+	assert.Equal(t, "/etc/schema.sql", tf.config.SchemaFilepath)
+
+	os.Setenv(envDatabaseURL, "mysql://root@localhost/test_bar")
+	os.Setenv(envDataDir, "fixtures")
+	defer os.Unsetenv(envDatabaseURL)
+	defer os.Unsetenv(envDataDir)
+
+	tf = &TestFixture{config: new(Config)}
+	tf.applyConfigFile(pkgDir)
+	assert.Equal(t, "test_bar", tf.config.DatabaseURL.DBName())
+	// relative to the config file rather than the package
+	assert.Equal(t, filepath.Join(root, "fixtures"), tf.config.FixtureDataDir)
+
+	// or to the module root without a config file
+	os.Remove(filepath.Join(root, configFileName))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(root, "go.mod"), nil, 0644))
+	tf = &TestFixture{config: new(Config)}
+	tf.applyConfigFile(pkgDir)
+	assert.Equal(t, filepath.Join(root, "fixtures"), tf.config.FixtureDataDir)
+
+	assert.PanicsWithValue(t, "invalid db name 'foo': test db name must starts with 'test_'", func() {
+		os.Setenv(envDatabaseURL, "mysql://root@localhost/foo")
+		(&TestFixture{config: new(Config)}).applyConfigFile(pkgDir)
+	})
+}
+
+func Test_applyConfigFile_Invalid(t *testing.T) {
+	dir := tempDir(t)
+	assert.PanicsWithValue(t, "database not configured by 'fixture.yml' or $FIXTURE_DATABASE_URL", func() {
+		(&TestFixture{config: new(Config)}).applyConfigFile(dir)
+	})
+
+	writeConfigFile(t, dir, "databse: mysql://root@localhost/test_foo")
+	assert.Panics(t, func() {
+		(&TestFixture{config: new(Config)}).applyConfigFile(dir)
+	})
+}
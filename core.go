@@ -1,15 +1,23 @@
 package fixture
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
 	"path"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/iFaceless/fixture/loaders"
 )
 
 const (
-	defaultSchemaName = "schema.sql"
+	defaultSchemaName   = "schema.sql"
+	defaultMaxIdleConns = 2
+	// tableVariantSep separates a table from the variant of its fixture
+	// data, 'user@admin' loads 'user.admin.yml' into the table 'user'.
+	tableVariantSep = "@"
 )
 
 type DataFormat int
@@ -41,15 +49,43 @@ func LookupDataFormatByExt(ext string) (DataFormat, bool) {
 	return ret, ok
 }
 
+// IsolationMode decides how the fixture data of a scope is isolated
+// from other tests.
+type IsolationMode int
+
+const (
+	// TruncateIsolation commits fixture data and truncates the selected
+	// tables when the scope is cleared.
+	TruncateIsolation IsolationMode = iota
+	// TransactionIsolation loads fixture data inside a transaction and
+	// rolls it back when the scope is cleared. Code under test must use
+	// the handle returned by Scope.Tx or Scope.DB to see the data.
+	TransactionIsolation
+)
+
 type TestFixture struct {
-	config *Config
-	tables []*table
+	config    *Config
+	tables    []*table
+	pool      *dbPool
+	mu        sync.Mutex
+	dbs       map[string]*sql.DB
+	hooks     map[HookEvent][]Hook
+	scenarios map[string]*scenario
+	// createdDatabases are dropped at Close by the DropDatabaseOnClose
+	// option.
+	createdDatabases []*DatabaseURL
+	// sharedDBName is the database of the SharedDB option.
+	sharedDBName string
+	// heldLocks are the named locks of the ProcessLock option, they are
+	// shared by the scopes of the fixture.
+	heldLocks map[string]*heldLock
 }
 
 func New(opts ...Option) *TestFixture {
 	defaultConfig := &Config{
 		FixtureDataDir: ".",
 		SchemaFilepath: path.Join(".", defaultSchemaName),
+		MaxIdleConns:   defaultMaxIdleConns,
 	}
 	tf := &TestFixture{
 		config: defaultConfig,
@@ -60,15 +96,54 @@ func New(opts ...Option) *TestFixture {
 	}
 
 	panicOnErr(tf.config.Validate())
+	if tf.config.DryRun != nil {
+		tf.tables = parseSchemaFile(tf.config.SchemaFilepath)
+		tf.applyTablePrefix()
+		panicOnErr(tf.loadScenarios())
+		panicOnErr(tf.createDryRun())
+		return tf
+	}
+
+	if tf.config.ReadyTimeout > 0 {
+		panicOnErr(tf.waitReady())
+	}
+	if tf.config.Safety != nil && tf.config.DatabaseURL != nil {
+		panicOnErr(tf.config.Safety.checkHost(tf.config.DatabaseURL))
+	}
+	if tf.config.CreateDatabase && tf.config.DatabaseURL != nil {
+		tf.createDatabase(tf.config.DatabaseURL)
+	}
+	if tf.config.DB != nil {
+		tf.checkSharedDB()
+	}
 
 	tf.tables = parseSchemaFile(tf.config.SchemaFilepath)
-	tf.createTables()
+	tf.applyTablePrefix()
+	panicOnErr(tf.loadScenarios())
+	if tf.config.PoolSize > 0 {
+		tf.pool = newDBPool(tf.config.DatabaseURL, tf.config.PoolSize, tf.config.PoolTimeout)
+		for _, dbURL := range tf.pool.urls {
+			tf.createDatabase(dbURL)
+		}
+	}
+
+	for _, dbURL := range tf.databaseURLs() {
+		if tf.config.Safety != nil {
+			panicOnErr(tf.checkDatabase(context.Background(), dbURL))
+		}
+		panicOnErr(tf.createTables(context.Background(), dbURL))
+	}
 
 	return tf
 }
 
+// String describes the fixture by its database url, with the password
+// masked.
 func (tf *TestFixture) String() string {
-	return fmt.Sprintf("TestFixture(url='%s')", tf.config.DatabaseURL)
+	if tf.config.DatabaseURL == nil {
+		return "TestFixture(url='<shared db>')"
+	}
+	return fmt.Sprintf("TestFixture(url='%s')", tf.config.DatabaseURL.Redacted())
 }
 
 func (tf *TestFixture) Config() *Config {
@@ -83,80 +158,357 @@ func (tf *TestFixture) TableNames() []string {
 	return names
 }
 
+// Use loads fixture data of the tables, it panics on any error.
 func (tf *TestFixture) Use(tableNames ...string) *Scope {
+	scope, err := tf.UseContext(context.Background(), tableNames...)
+	panicOnErr(err)
+	return scope
+}
+
+// UseContext is like Use but returns the database errors, every statement
+// runs under ctx and the Timeout option.
+func (tf *TestFixture) UseContext(ctx context.Context, tableNames ...string) (*Scope, error) {
+	return newScope(ctx, &Scope{tf: tf}, tableNames)
+}
+
+// DropTables drops all the test tables, from every database of the pool
+// if it's enabled. Errors are logged, including the refusals of the Safety
+// option.
+func (tf *TestFixture) DropTables() {
+	if err := tf.DropTablesContext(context.Background()); err != nil {
+		tf.logger().Error("failed to drop tables", "error", err)
+	}
+}
+
+// DropTablesContext is like DropTables but returns the first error.
+func (tf *TestFixture) DropTablesContext(ctx context.Context) error {
+	if tf.config.DryRun != nil {
+		return tf.dropDryRun()
+	}
+
+	var firstErr error
+	for _, dbURL := range tf.databaseURLs() {
+		if err := tf.dropTables(ctx, dbURL); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (tf *TestFixture) dropTables(ctx context.Context, dbURL *DatabaseURL) error {
+	tf.logger().Info("drop tables", "database", dbNameOf(dbURL), "count", len(tf.tables))
+	db := tf.dbFor(dbURL)
+
+	lock, err := tf.lock(ctx, dbURL, tf.lockNames(dbURL, tf.tables))
+	if err != nil {
+		return err
+	}
+	defer tf.unlock(lock)
+
+	// the database may have changed since the fixture was created
+	if tf.config.Safety != nil {
+		if err := tf.checkDatabase(ctx, dbURL); err != nil {
+			return err
+		}
+	}
+
+	for _, tb := range tf.tables {
+		// a missing table can't be counted, nor is it at risk
+		if err := tf.checkRowLimit(ctx, db, tb); err != nil {
+			if _, refused := err.(*SafetyError); refused {
+				return err
+			}
+		}
+	}
+
+	var firstErr error
+	for _, tb := range tf.tables {
+		_, err := tf.exec(ctx, db, "drop", tb.name, "DROP TABLE "+tb.sqlName)
+		if err != nil {
+			tf.logger().Error("failed to drop table", "table", tb.name, "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+
+		_, err = tf.exec(ctx, db, "drop", tb.name, "DROP TABLE IF EXISTS "+snapshotTableName(tb))
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// dbNameOf names the database in logs, the url is nil for a shared handle.
+func dbNameOf(dbURL *DatabaseURL) string {
+	if dbURL == nil {
+		return "<shared db>"
+	}
+	return dbURL.DBName()
+}
+
+// databaseURLs returns the databases holding test tables, which are the
+// clones if the pool is enabled.
+func (tf *TestFixture) databaseURLs() []*DatabaseURL {
+	if tf.pool != nil {
+		return tf.pool.urls
+	}
+	return []*DatabaseURL{tf.config.DatabaseURL}
+}
+
+func (tf *TestFixture) createTables(ctx context.Context, dbURL *DatabaseURL) error {
+	tf.logger().Info("create tables", "database", dbNameOf(dbURL), "count", len(tf.tables))
+	db := tf.dbFor(dbURL)
+
+	lock, err := tf.lock(ctx, dbURL, tf.lockNames(dbURL, tf.tables))
+	if err != nil {
+		return err
+	}
+	defer tf.unlock(lock)
+
+	for _, tb := range tf.tables {
+		_, err := tf.exec(ctx, db, "create", tb.name, tb.createSQL)
+		if err == nil {
+			continue
+		}
+
+		if !strings.Contains(err.Error(), "already exists") {
+			return err
+		}
+
+		tf.logger().Info("table already existed, try to clear existing data now", "table", tb.name)
+		if err := tf.clearTable(ctx, db, tb, tf.clearStrategy(tb)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selectTables looks up the tables by name, a name may come with the
+// variant of its fixture data such as 'user@admin'. The variants are
+// returned by table name, empty if there is none.
+func (tf *TestFixture) selectTables(tableNames []string) ([]*table, map[string]string) {
 	selectedTables := make([]*table, 0)
+	variants := make(map[string]string)
 	for _, name := range tableNames {
+		name, variant := splitTableVariant(name)
 		table := tf.lookupTable(name)
 		if table == nil {
 			panic(fmt.Sprintf("table '%s' not found", name))
 		}
+		if _, ok := variants[name]; ok {
+			panic(fmt.Sprintf("table '%s' selected more than once", name))
+		}
 
+		variants[name] = variant
 		selectedTables = append(selectedTables, table)
 	}
-
-	return newScope(tf, selectedTables)
+	return selectedTables, variants
 }
 
-// DropTables drops all the test tables
-func (tf *TestFixture) DropTables() {
-	log.Printf("fixture: drop %d tables", len(tf.tables))
-	db := getDB(tf)
-	defer db.Close()
+// splitTableVariant splits 'user@admin' into 'user' and 'admin'.
+func splitTableVariant(name string) (string, string) {
+	if i := strings.Index(name, tableVariantSep); i >= 0 {
+		return name[:i], name[i+len(tableVariantSep):]
+	}
+	return name, ""
+}
 
-	for _, tb := range tf.tables {
-		_, err := db.Exec("DROP TABLE " + tb.name)
-		if err != nil {
-			log.Printf("fixture: failed to drop table '%s': %s", tb.name, err)
+func (tf *TestFixture) lookupTable(name string) *table {
+	for _, item := range tf.tables {
+		if item.name == name {
+			return item
 		}
 	}
+	return nil
 }
 
-func (tf *TestFixture) createTables() {
-	log.Printf("fixture: create %d tables", len(tf.tables))
-	db := getDB(tf)
-	defer db.Close()
+type Scope struct {
+	tf              *TestFixture
+	url             *DatabaseURL
+	pooled          bool
+	parent          *Scope
+	children        []*Scope
+	selectedTables  []*table
+	tx              *sql.Tx
+	savepoint       string
+	savepointSeq    int
+	cleared         bool
+	hooks           map[HookEvent][]Hook
+	clearStrategies map[string]ClearStrategy
+	insertedKeys    map[string][][]string
+	snapshot        *snapshot
+	anyTable        bool
+	loadedTables    map[string]*loadedTable
+	variants        map[string]string
+	testDataDirs    []string
+	files           map[string]string
+	extraSQL        []string
+	lock            *processLock
+}
 
-	tx, err := db.Begin()
-	panicOnErr(err)
+// newScope selects the tables for the scope and loads them, the scope
+// comes with the fixture and its parent if any.
+func newScope(ctx context.Context, scope *Scope, tableNames []string) (*Scope, error) {
+	scope.selectedTables, scope.variants = scope.tf.selectTables(tableNames)
+	if scope.tf.config.DryRun != nil {
+		if err := scope.loadDryRun(); err != nil {
+			return nil, err
+		}
+		if scope.parent != nil {
+			scope.parent.children = append(scope.parent.children, scope)
+		}
+		return scope, nil
+	}
+
+	if err := scope.checkout(ctx); err != nil {
+		return nil, err
+	}
 
 	defer func() {
 		if err := recover(); err != nil {
-			tx.Rollback()
+			scope.checkin()
 			panic(err)
 		}
 	}()
 
-	for _, tb := range tf.tables {
-		_, err := tx.Exec(tb.createSQL)
+	if err := scope.load(ctx); err != nil {
+		scope.checkin()
+		return nil, err
+	}
+
+	if scope.parent != nil {
+		scope.parent.children = append(scope.parent.children, scope)
+	}
+	return scope, nil
+}
+
+// Use creates a child scope loading fixture data of more tables on top
+// of the current one. The child runs inside a transaction, nested by a
+// savepoint when the current scope is transactional as well, so clearing
+// it leaves the data of the current scope intact. It maps naturally onto
+// subtests started by t.Run.
+func (s *Scope) Use(tableNames ...string) *Scope {
+	scope, err := s.UseContext(context.Background(), tableNames...)
+	panicOnErr(err)
+	return scope
+}
+
+// UseContext is like Use but returns the database errors.
+func (s *Scope) UseContext(ctx context.Context, tableNames ...string) (*Scope, error) {
+	return newScope(ctx, &Scope{tf: s.tf, parent: s}, tableNames)
+}
+
+// DatabaseURL returns the url of the database holding the fixture data,
+// which is a clone checked out from the pool if it's enabled.
+func (s *Scope) DatabaseURL() *DatabaseURL {
+	return s.url
+}
+
+// DSN returns the data source name code under test should connect to,
+// it's empty if the fixture only knows the handle given by SharedDB.
+func (s *Scope) DSN() string {
+	if s.url == nil {
+		return ""
+	}
+	return s.url.DSN()
+}
+
+// Tx returns the transaction holding the fixture data, it's nil unless
+// the TestFixture uses TransactionIsolation or the scope is a child scope.
+func (s *Scope) Tx() *sql.Tx {
+	return s.tx
+}
+
+// DB returns the handle code under test should use to see the fixture data.
+func (s *Scope) DB() DBTX {
+	if s.tx != nil {
+		return s.tx
+	}
+	return s.tf.dbFor(s.url)
+}
+
+// checkout picks the database of the scope, child scopes always share
+// the one of their parent, and takes the locks of the ProcessLock option.
+func (s *Scope) checkout(ctx context.Context) error {
+	switch {
+	case s.parent != nil:
+		s.url = s.parent.url
+	case s.tf.pool != nil:
+		dbURL, err := s.tf.pool.acquire(ctx)
 		if err != nil {
-			if strings.Contains(fmt.Sprintf("%s", err), "already exists") {
-				log.Printf("fixture: table '%s' already existed, try to clear existing data now", tb.name)
-				db.Exec("TRUNCATE TABLE " + tb.name)
-			}
+			return err
 		}
+		s.url = dbURL
+		s.pooled = true
+	default:
+		s.url = s.tf.config.DatabaseURL
 	}
 
-	tx.Commit()
+	lock, err := s.tf.lock(ctx, s.url, s.lockNames())
+	if err != nil {
+		s.checkin()
+		return err
+	}
+	s.lock = lock
+	return nil
 }
 
-func (tf *TestFixture) lookupTable(name string) *table {
-	for _, item := range tf.tables {
-		if item.name == name {
-			return item
+// checkin releases the locks of the scope, and returns the database to
+// the pool if it's checked out from it.
+func (s *Scope) checkin() {
+	if s.lock != nil {
+		s.tf.unlock(s.lock)
+		s.lock = nil
+	}
+
+	if s.pooled {
+		s.pooled = false
+		s.tf.pool.release(s.url)
+	}
+}
+
+func (s *Scope) load(ctx context.Context) error {
+	switch {
+	case s.parent != nil && s.parent.tx != nil:
+		s.tx = s.parent.tx
+		if err := s.setSavepoint(ctx); err != nil {
+			return err
+		}
+	case s.parent != nil || s.tf.config.Isolation == TransactionIsolation:
+		if err := s.begin(); err != nil {
+			return err
 		}
 	}
+	return s.insertFixtureData(ctx)
+}
+
+func (s *Scope) begin() error {
+	// The transaction outlives the call which creates the scope, so it
+	// must not be bound to a context that may be canceled in the meantime.
+	tx, err := s.tf.dbFor(s.url).BeginTx(context.Background(), nil)
+	if err != nil {
+		return &OpError{Op: "begin", Statement: "BEGIN", Err: err}
+	}
+	s.tx = tx
 	return nil
 }
 
-type Scope struct {
-	tf             *TestFixture
-	selectedTables []*table
+func (s *Scope) setSavepoint(ctx context.Context) error {
+	s.savepoint = s.nextSavepoint("fixture_scope")
+	_, err := s.tf.exec(ctx, s.tx, "savepoint", "", "SAVEPOINT "+s.savepoint)
+	return err
 }
 
-func newScope(tf *TestFixture, tables []*table) *Scope {
-	scope := &Scope{tf, tables}
-	scope.insertFixtureData()
-	return scope
+// nextSavepoint names a savepoint uniquely within the transaction of the
+// scope, which is counted by the root scope owning the transaction.
+func (s *Scope) nextSavepoint(prefix string) string {
+	root := s
+	for root.parent != nil && root.parent.tx == root.tx {
+		root = root.parent
+	}
+	root.savepointSeq++
+	return fmt.Sprintf("%s_%d", prefix, root.savepointSeq)
 }
 
 func (s *Scope) Test(testFunc func()) {
@@ -164,66 +516,273 @@ func (s *Scope) Test(testFunc func()) {
 	testFunc()
 }
 
-// Clear just drop the selected tables, simple and clear
+// Clear just drop the selected tables, simple and clear. Child scopes
+// which are still alive are cleared first. A transactional scope is
+// rolled back instead, to its savepoint if it's nested in its parent's
+// transaction. Errors are logged, since Clear usually runs deferred, and
+// the scope is left loaded if its tables failed to clear.
 func (s *Scope) Clear() {
-	log.Printf("fixture: clear %d selected tables", len(s.selectedTables))
-	db := getDB(s.tf)
-	defer db.Close()
+	if err := s.ClearContext(context.Background()); err != nil {
+		s.tf.logger().Error("failed to clear scope", "tables", strings.Join(s.tableNames(), ","), "error", err)
+	}
+}
+
+// ClearContext is like Clear but returns the first error. An error of a
+// BeforeClear hook or of clearing the tables leaves the scope loaded, with
+// its database and locks held until it's cleared again.
+func (s *Scope) ClearContext(ctx context.Context) error {
+	if s.cleared {
+		return nil
+	}
+	if s.tf.config.DryRun != nil {
+		return s.clearDryRun(ctx)
+	}
+
+	err := s.runHooks(&HookContext{Context: ctx, Event: BeforeClear, Tx: s.DB()})
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for i := len(s.children) - 1; i >= 0; i-- {
+		if err := s.children[i].ClearContext(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	transactional := s.tx != nil
+	if err := s.clear(ctx); err != nil {
+		if firstErr == nil {
+			firstErr = err
+		}
+		// tables failed to clear stay with the scope, so a dirty database
+		// goes back to neither the pool nor other processes, and the scope
+		// may be cleared again
+		if !transactional {
+			return firstErr
+		}
+	}
+
+	s.cleared = true
+	defer s.checkin()
 
+	err = s.runHooks(&HookContext{Context: ctx, Event: AfterClear, Tx: s.DB()})
+	if err != nil && firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
+}
+
+func (s *Scope) clear(ctx context.Context) error {
+	if s.savepoint != "" {
+		s.tf.logger().Info("rollback selected tables to savepoint", "count", len(s.selectedTables), "savepoint", s.savepoint)
+		err := s.rollbackToSavepoint(ctx)
+		if err != nil {
+			s.tf.logger().Error("failed to rollback to savepoint", "savepoint", s.savepoint, "error", err)
+		}
+		s.tx = nil
+		return err
+	}
+
+	if s.tx != nil {
+		s.tf.logger().Info("rollback selected tables", "count", len(s.selectedTables))
+		err := s.tx.Rollback()
+		if err != nil {
+			s.tf.logger().Error("failed to rollback transaction", "error", err)
+			err = &OpError{Op: "rollback", Statement: "ROLLBACK", Err: err}
+		}
+		s.tx = nil
+		return err
+	}
+
+	s.tf.logger().Info("clear selected tables", "count", len(s.selectedTables))
+	db := s.tf.dbFor(s.url)
+
+	var firstErr error
 	for _, tb := range s.selectedTables {
-		_, err := db.Exec("TRUNCATE TABLE " + tb.name)
+		var err error
+		if strategy := s.clearStrategy(tb); strategy == ClearInserted {
+			err = s.clearInserted(ctx, db, tb)
+		} else {
+			err = s.tf.clearTable(ctx, db, tb, strategy)
+		}
 		if err != nil {
-			log.Printf("fixture: failed to clear table '%s': %s", tb.name, err)
+			s.tf.logger().Error("failed to clear table", "table", tb.name, "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
 		}
 	}
+	return firstErr
 }
 
-func (s *Scope) insertFixtureData() {
-	db := getDB(s.tf)
-	defer db.Close()
+func (s *Scope) rollbackToSavepoint(ctx context.Context) error {
+	_, err := s.tf.exec(ctx, s.tx, "rollback", "", "ROLLBACK TO SAVEPOINT "+s.savepoint)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.tf.exec(ctx, s.tx, "release", "", "RELEASE SAVEPOINT "+s.savepoint)
+	return err
+}
+
+// findFixtureData finds the fixture file of a table given by the scenario
+// of the scope, or else in the directories of the test which created the
+// scope or its closest ancestor, and at last in DataDir.
+func (s *Scope) findFixtureData(tb *table) *fixtureData {
+	if file, ok := s.files[tb.name]; ok {
+		return &fixtureData{
+			Path:   path.Join(s.tf.config.FixtureDataDir, file),
+			Format: extToDataFmtMapping[path.Ext(file)],
+		}
+	}
+
+	variant := s.variants[tb.name]
+	for scope := s; scope != nil; scope = scope.parent {
+		if scope.testDataDirs == nil {
+			continue
+		}
 
-	tx, err := db.Begin()
+		for _, dir := range scope.testDataDirs {
+			if found := searchFixtureData(dir, tb.name, variant); found != nil {
+				return found
+			}
+		}
+		break
+	}
+	return findFixtureData(s.tf.config.FixtureDataDir, tb, variant)
+}
+
+// renderFixtureData finds the fixture file of a table and renders its
+// insert statement with the loader, the parsed rows are recorded for
+// FixtureRows if record is set. The data is nil if there's no file.
+func (s *Scope) renderFixtureData(tb *table, record bool) (*fixtureData, string) {
+	fixtureData := s.findFixtureData(tb)
+	if fixtureData == nil {
+		s.tf.logger().Warn("failed to find fixture data", "table", tb.name)
+		return nil, ""
+	}
+
+	loader := LookupLoader(fixtureData.Format)
+	if contentLoader, ok := loader.(ContentLoader); ok {
+		content, err := contentLoader.Parse(fixtureData.Path)
+		panicOnErr(err)
+		if record {
+			s.recordRows(tb, content.Rows)
+		}
+		content.Table = s.tf.TableName(content.Table)
+		return fixtureData, loaders.GenSQL(content)
+	}
+
+	sqlStr, err := loader.Load(fixtureData.Path)
 	panicOnErr(err)
+	return fixtureData, s.tf.prefixTableNames(sqlStr)
+}
 
-	defer func() {
-		if err := recover(); err != nil {
+func (s *Scope) insertFixtureData(ctx context.Context) (err error) {
+	tx := s.tx
+	if tx == nil {
+		tx, err = s.tf.dbFor(s.url).BeginTx(ctx, nil)
+		if err != nil {
+			return &OpError{Op: "begin", Statement: "BEGIN", Err: err}
+		}
+	}
+
+	abort := func() {
+		if s.savepoint != "" {
+			s.rollbackToSavepoint(context.Background())
+		} else {
 			tx.Rollback()
-			panic(err)
+		}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			abort()
+			panic(r)
+		}
+		if err != nil {
+			abort()
 		}
 	}()
 
+	if err := s.runHooks(&HookContext{Context: ctx, Event: BeforeLoad, Tx: tx}); err != nil {
+		return err
+	}
+
 	for _, tb := range s.selectedTables {
-		fixtureData := findFixtureData(s.tf.config.FixtureDataDir, tb)
+		start := time.Now()
+		fixtureData, sqlStr := s.renderFixtureData(tb, true)
 		if fixtureData == nil {
-			log.Printf("failed to find fixture data for table '%s'", tb.name)
 			continue
 		}
 
-		log.Printf("insert fixture data for table '%s' from file '%s'", tb.name, fixtureData.Path)
-		loader := LookupLoader(fixtureData.Format)
-		sqlStr, err := loader.Load(fixtureData.Path)
-		panicOnErr(err)
+		var rows int64
+		insert := func() error {
+			if sqlStr == "" {
+				return nil
+			}
 
-		if sqlStr != "" {
-			_, err = tx.Exec(sqlStr)
+			result, err := s.tf.exec(ctx, tx, "insert", tb.name, sqlStr)
 			if err != nil {
-				log.Panicf("failed to insert fixture data for table '%s': %s", tb.name, err)
+				return err
 			}
+			rows, _ = result.RowsAffected()
+			return nil
+		}
+
+		if s.tx == nil && s.clearStrategy(tb) == ClearInserted {
+			err = s.trackInserted(ctx, tx, tb, insert)
+		} else {
+			err = insert()
+		}
+		if err != nil {
+			return err
+		}
+
+		s.tf.logger().Info("insert fixture data",
+			"table", tb.name,
+			"file", fixtureData.Path,
+			"rows", rows,
+			"duration", time.Since(start),
+		)
+
+		err = s.runHooks(&HookContext{
+			Context:     ctx,
+			Event:       AfterLoadTable,
+			Table:       tb.name,
+			FixturePath: fixtureData.Path,
+			Tx:          tx,
+		})
+		if err != nil {
+			return err
 		}
 	}
 
-	tx.Commit()
-}
+	for _, query := range s.extraSQL {
+		if _, err := s.tf.exec(ctx, tx, "exec", "", s.tf.prefixTableNames(query)); err != nil {
+			return err
+		}
+	}
 
-func getDB(tf *TestFixture) *sql.DB {
-	db, err := sql.Open(tf.config.DatabaseURL.Driver(), tf.config.DatabaseURL.DSN())
-	panicOnErr(err)
-	return db
+	if err := s.runHooks(&HookContext{Context: ctx, Event: AfterLoad, Tx: tx}); err != nil {
+		return err
+	}
+
+	if tx != s.tx {
+		if err := tx.Commit(); err != nil {
+			return &OpError{Op: "commit", Statement: "COMMIT", Err: err}
+		}
+	}
+	return nil
 }
 
 type table struct {
-	name      string
-	createSQL string
+	name string
+	// sqlName is the name of the table in the database, which differs
+	// from the name of the schema with the TablePrefix option.
+	sqlName    string
+	createSQL  string
+	primaryKey []string
 }
 
 type fixtureData struct {
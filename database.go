@@ -0,0 +1,106 @@
+package fixture
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// errDatabaseExists is the error number of MySQL for creating a database
+// which exists.
+const errDatabaseExists = 1007
+
+// charsetRule matches the names of charsets and collations, which can't
+// be passed as arguments of a statement.
+var charsetRule = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// createDatabase creates the test database of the url unless it exists,
+// with the charset and collation of the CreateDatabase option if any.
+func (tf *TestFixture) createDatabase(dbURL *DatabaseURL) {
+	dbName := dbURL.DBName()
+	checkTestDBName(dbName)
+
+	serverURL, err := dbURL.WithDBName("")
+	panicOnErr(err)
+
+	db := getDB(serverURL)
+	defer db.Close()
+
+	// only the databases created here are dropped or marked, an existing
+	// one may hold data of others
+	_, err = db.Exec(createDatabaseSQL(dbName, tf.config.Charset, tf.config.Collation))
+	if isDatabaseExistsErr(err) {
+		return
+	}
+	panicOnErr(err)
+
+	tf.logger().Debug("database created", "database", dbName)
+	tf.createdDatabases = append(tf.createdDatabases, dbURL)
+
+	// mark the database as one of the fixture
+	if tf.config.Safety != nil && tf.config.Safety.MarkerTable != "" {
+		_, err = db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s.%s (id INT)",
+			quoteName(dbName), quoteName(tf.config.Safety.MarkerTable)))
+		panicOnErr(err)
+	}
+}
+
+// dropCreatedDatabases drops the databases created by the fixture.
+func (tf *TestFixture) dropCreatedDatabases() error {
+	var firstErr error
+	for _, dbURL := range tf.createdDatabases {
+		if err := dropDatabase(dbURL); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		tf.logger().Info("database dropped", "database", dbURL.DBName())
+	}
+	tf.createdDatabases = nil
+	return firstErr
+}
+
+func dropDatabase(dbURL *DatabaseURL) error {
+	dbName := dbURL.DBName()
+	checkTestDBName(dbName)
+
+	serverURL, err := dbURL.WithDBName("")
+	if err != nil {
+		return err
+	}
+
+	db := getDB(serverURL)
+	defer db.Close()
+
+	query := "DROP DATABASE IF EXISTS " + quoteName(dbName)
+	if _, err := db.Exec(query); err != nil {
+		return &OpError{Op: "drop database", Statement: query, Err: err}
+	}
+	return nil
+}
+
+func createDatabaseSQL(dbName, charset, collation string) string {
+	query := "CREATE DATABASE " + quoteName(dbName)
+	if charset != "" {
+		query += " CHARACTER SET " + charset
+	}
+	if collation != "" {
+		query += " COLLATE " + collation
+	}
+	return query
+}
+
+// isDatabaseExistsErr tells whether creating a database failed because it
+// exists, which is checked by the server rather than beforehand so that
+// concurrent fixtures don't both take it as created by them.
+func isDatabaseExistsErr(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == errDatabaseExists
+}
+
+func checkTestDBName(dbName string) {
+	if !strings.HasPrefix(dbName, "test_") {
+		panic(fmt.Sprintf("invalid db name '%s': test db name must starts with 'test_'", dbName))
+	}
+}
@@ -0,0 +1,99 @@
+package fixture
+
+import (
+	"context"
+	"database/sql"
+)
+
+// dbFor returns the long-lived handle of a database managed by the
+// fixture, it's opened on first use and kept until Close.
+func (tf *TestFixture) dbFor(dbURL *DatabaseURL) *sql.DB {
+	if dbURL == tf.config.DatabaseURL && tf.config.DB != nil {
+		return tf.config.DB
+	}
+
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
+
+	if db, ok := tf.dbs[dbURL.DSN()]; ok {
+		return db
+	}
+
+	db := getDB(dbURL)
+	db.SetMaxOpenConns(tf.config.MaxOpenConns)
+	db.SetMaxIdleConns(tf.config.MaxIdleConns)
+	db.SetConnMaxLifetime(tf.config.ConnMaxLifetime)
+
+	if tf.dbs == nil {
+		tf.dbs = make(map[string]*sql.DB)
+	}
+	tf.dbs[dbURL.DSN()] = db
+	return db
+}
+
+// Close closes the database handles opened by the fixture, a handle
+// shared by the SharedDB option is left open for its owner. It drops the
+// databases created by the fixture as well with the DropDatabaseOnClose
+// option.
+func (tf *TestFixture) Close() error {
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
+
+	var firstErr error
+	for dsn, db := range tf.dbs {
+		if err := db.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(tf.dbs, dsn)
+	}
+
+	if tf.config.DropDatabase {
+		if err := tf.dropCreatedDatabases(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// checkSharedDB makes sure the shared handle connects to a test database,
+// just like the guard of the Database option.
+func (tf *TestFixture) checkSharedDB() {
+	var dbName sql.NullString
+	err := tf.config.DB.QueryRow("SELECT DATABASE()").Scan(&dbName)
+	panicOnErr(err)
+
+	checkTestDBName(dbName.String)
+	tf.sharedDBName = dbName.String
+}
+
+// exec runs a statement of an operation on table, bounded by the Timeout
+// option. The error names the table and the statement.
+func (tf *TestFixture) exec(ctx context.Context, db DBTX, op, tableName, query string, args ...interface{}) (sql.Result, error) {
+	ctx, cancel := tf.withTimeout(ctx)
+	defer cancel()
+
+	result, err := db.ExecContext(ctx, query, args...)
+	if err == nil {
+		return result, nil
+	}
+
+	// the driver may report a canceled query as a broken connection
+	if ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	return nil, &OpError{Op: op, Table: tableName, Statement: query, Err: err}
+}
+
+// withTimeout bounds ctx by the Timeout option if it's set.
+func (tf *TestFixture) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if tf.config.Timeout > 0 {
+		return context.WithTimeout(ctx, tf.config.Timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+func getDB(dbURL *DatabaseURL) *sql.DB {
+	db, err := sql.Open(dbURL.Driver(), dbURL.DSN())
+	panicOnErr(err)
+	return db
+}
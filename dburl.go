@@ -1,6 +1,8 @@
 package fixture
 
 import (
+	"net/url"
+
 	"github.com/xo/dburl"
 )
 
@@ -29,6 +31,22 @@ func (dbURL *DatabaseURL) DBName() string {
 	return ""
 }
 
+// WithDBName returns a copy of the url connecting to another database,
+// an empty name connects to the server without selecting a database.
+func (dbURL *DatabaseURL) WithDBName(name string) (*DatabaseURL, error) {
+	u := dbURL.url.URL
+	u.Path, u.RawPath = "", ""
+	if name != "" {
+		u.Path = "/" + name
+	}
+	return Parse(u.String())
+}
+
+// Hostname returns the host of the url without the port.
+func (dbURL *DatabaseURL) Hostname() string {
+	return dbURL.url.Hostname()
+}
+
 func (dbURL *DatabaseURL) Driver() string {
 	return dbURL.url.Driver
 }
@@ -37,6 +55,19 @@ func (dbURL *DatabaseURL) DSN() string {
 	return dbURL.url.DSN
 }
 
+// Redacted returns the url with its password masked, it's safe to log.
+func (dbURL *DatabaseURL) Redacted() string {
+	u := dbURL.url.URL
+	if u.User == nil {
+		return u.String()
+	}
+
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), "xxxxx")
+	}
+	return u.String()
+}
+
 func (dbURL *DatabaseURL) String() string {
 	return dbURL.url.String()
 }
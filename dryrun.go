@@ -0,0 +1,140 @@
+package fixture
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// RenderSQL writes the statements of the scope in the order they run, the
+// creation of the selected tables, the inserts of their fixture data and
+// the clearing of them. The fixture files are parsed by the loaders again
+// but nothing is sent to the database.
+func (s *Scope) RenderSQL(w io.Writer) error {
+	stmts := s.createStatements()
+	stmts = append(stmts, s.insertStatements(false)...)
+	stmts = append(stmts, s.clearStatements()...)
+	return writeStatements(w, stmts)
+}
+
+// RenderSQL writes the statements of a scope of the tables like
+// Scope.RenderSQL, without creating the scope, so neither the DryRun
+// option nor a database is involved.
+func (tf *TestFixture) RenderSQL(w io.Writer, tableNames ...string) error {
+	scope := &Scope{tf: tf}
+	scope.selectedTables, scope.variants = tf.selectTables(tableNames)
+	return scope.RenderSQL(w)
+}
+
+// transactional tells whether the scope loads its data in a transaction,
+// which is rolled back instead of clearing the tables.
+func (s *Scope) transactional() bool {
+	return s.parent != nil || s.tf.config.Isolation == TransactionIsolation
+}
+
+func (s *Scope) createStatements() []string {
+	stmts := make([]string, 0, len(s.selectedTables))
+	for _, tb := range s.selectedTables {
+		stmts = append(stmts, tb.createSQL)
+	}
+	return stmts
+}
+
+// insertStatements renders the inserts of the fixture data and the extra
+// sql of a scenario, the rows are recorded for FixtureRows if record is set.
+func (s *Scope) insertStatements(record bool) []string {
+	stmts := make([]string, 0, len(s.selectedTables)+len(s.extraSQL)+1)
+	switch {
+	case s.savepoint != "":
+		stmts = append(stmts, "SAVEPOINT "+s.savepoint)
+	case s.transactional():
+		stmts = append(stmts, "BEGIN")
+	}
+
+	for _, tb := range s.selectedTables {
+		fixtureData, sqlStr := s.renderFixtureData(tb, record)
+		if fixtureData == nil || sqlStr == "" {
+			continue
+		}
+		stmts = append(stmts, fmt.Sprintf("-- %s: %s\n%s", tb.name, fixtureData.Path, strings.TrimSpace(sqlStr)))
+	}
+
+	for _, query := range s.extraSQL {
+		stmts = append(stmts, s.tf.prefixTableNames(query))
+	}
+	return stmts
+}
+
+func (s *Scope) clearStatements() []string {
+	switch {
+	case s.savepoint != "":
+		return []string{"ROLLBACK TO SAVEPOINT " + s.savepoint}
+	case s.transactional():
+		return []string{"ROLLBACK"}
+	}
+
+	stmts := make([]string, 0, len(s.selectedTables))
+	for _, tb := range s.selectedTables {
+		if strategy := s.clearStrategy(tb); strategy == ClearInserted {
+			stmts = append(stmts, s.clearInsertedStatements(tb)...)
+		} else {
+			stmts = append(stmts, clearStatements(tb, strategy)...)
+		}
+	}
+	return stmts
+}
+
+// loadDryRun writes the inserts of a scope created under the DryRun option
+// in place of loading it.
+func (s *Scope) loadDryRun() error {
+	if s.parent != nil && s.parent.transactional() {
+		s.savepoint = s.nextSavepoint("fixture_scope")
+	}
+	return writeStatements(s.tf.config.DryRun, s.insertStatements(true))
+}
+
+// clearDryRun writes the statements clearing a scope created under the
+// DryRun option, after the ones of its children.
+func (s *Scope) clearDryRun(ctx context.Context) error {
+	var firstErr error
+	for i := len(s.children) - 1; i >= 0; i-- {
+		if err := s.children[i].ClearContext(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	s.cleared = true
+	if err := writeStatements(s.tf.config.DryRun, s.clearStatements()); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
+}
+
+// createDryRun writes the creation of all tables under the DryRun option.
+func (tf *TestFixture) createDryRun() error {
+	stmts := make([]string, 0, len(tf.tables))
+	for _, tb := range tf.tables {
+		stmts = append(stmts, tb.createSQL)
+	}
+	return writeStatements(tf.config.DryRun, stmts)
+}
+
+// dropDryRun writes the statements of DropTables under the DryRun option.
+func (tf *TestFixture) dropDryRun() error {
+	stmts := make([]string, 0, len(tf.tables))
+	for _, tb := range tf.tables {
+		stmts = append(stmts, "DROP TABLE "+tb.sqlName)
+	}
+	return writeStatements(tf.config.DryRun, stmts)
+}
+
+func writeStatements(w io.Writer, stmts []string) error {
+	for _, stmt := range stmts {
+		stmt = strings.TrimSuffix(strings.TrimSpace(stmt), ";")
+		if _, err := fmt.Fprintf(w, "%s;\n\n", stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
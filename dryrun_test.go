@@ -0,0 +1,119 @@
+package fixture
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDryRun(t *testing.T) {
+	var buf bytes.Buffer
+	tf := New(
+		SchemaFilepath(schemaFilepath),
+		DataDir(fixtureDataDir),
+		DryRun(&buf),
+	)
+	defer tf.Close()
+
+	for _, name := range tf.TableNames() {
+		assert.Contains(t, buf.String(), "CREATE TABLE `"+name+"`")
+	}
+
+	buf.Reset()
+	scope := tf.Use("user", "foo")
+	assert.True(t, strings.HasPrefix(buf.String(), "-- user: "+fixtureDataDir+"/user.yml\nINSERT INTO `user`"))
+	assert.Contains(t, buf.String(), "INSERT INTO `foo`")
+	assert.Equal(t, "1", scope.FixtureRow("user", "kary")["id"])
+
+	buf.Reset()
+	scope.Insert("bar", Values{"id": 1})
+	assert.Equal(t, "INSERT INTO `bar` (`id`)\nVALUES \n (1);\n\n", buf.String())
+
+	buf.Reset()
+	scope.Clear()
+	assert.Equal(t, "TRUNCATE TABLE user;\n\nTRUNCATE TABLE foo;\n\nTRUNCATE TABLE bar;\n\n", buf.String())
+}
+
+func TestDryRun_Transaction(t *testing.T) {
+	var buf bytes.Buffer
+	tf := New(
+		SchemaFilepath(schemaFilepath),
+		DataDir(fixtureDataDir),
+		Isolation(TransactionIsolation),
+		TablePrefix("t1_"),
+		DryRun(&buf),
+	)
+
+	buf.Reset()
+	scope := tf.Use("user")
+	child := scope.Use("foo")
+	assert.True(t, strings.HasPrefix(buf.String(), "BEGIN;\n\n"))
+	assert.Contains(t, buf.String(), "INSERT INTO `t1_user`")
+	assert.Contains(t, buf.String(), "SAVEPOINT fixture_scope_1;\n\n")
+
+	buf.Reset()
+	scope.Clear()
+	assert.Equal(t, "ROLLBACK TO SAVEPOINT fixture_scope_1;\n\nROLLBACK;\n\n", buf.String())
+	assert.True(t, child.cleared)
+
+	buf.Reset()
+	tf.DropTables()
+	assert.Contains(t, buf.String(), "DROP TABLE t1_user;\n\n")
+}
+
+func TestScope_RenderSQL(t *testing.T) {
+	var buf bytes.Buffer
+	tf := New(
+		SchemaFilepath(schemaFilepath),
+		DataDir(fixtureDataDir),
+		DryRun(&buf),
+	)
+
+	var rendered bytes.Buffer
+	scope := tf.Use("bar").ClearTableWith("bar", ClearDeleteResetAutoIncrement)
+	assert.Nil(t, scope.RenderSQL(&rendered))
+
+	stmts := strings.Split(strings.TrimSuffix(rendered.String(), ";\n\n"), ";\n\n")
+	if assert.Equal(t, 4, len(stmts)) {
+		assert.True(t, strings.HasPrefix(stmts[0], "CREATE TABLE `bar`"))
+		assert.True(t, strings.HasPrefix(stmts[1], "-- bar: "+fixtureDataDir+"/bar.sql\n"))
+		assert.Equal(t, "DELETE FROM bar", stmts[2])
+		assert.Equal(t, "ALTER TABLE bar AUTO_INCREMENT = 1", stmts[3])
+	}
+}
+
+func TestDryRun_ClearInserted(t *testing.T) {
+	var buf bytes.Buffer
+	tf := New(
+		SchemaFilepath(schemaFilepath),
+		DataDir(fixtureDataDir),
+		ClearWith(ClearInserted),
+		DryRun(&buf),
+	)
+
+	scope := tf.Use("user")
+	buf.Reset()
+	scope.Clear()
+	assert.Equal(t, "DELETE FROM user WHERE (`id`) IN (/* keys of the inserted rows */);\n\n", buf.String())
+}
+
+func (s *SuiteTestFixtureTester) TestFixture_RenderSQL() {
+	var rendered bytes.Buffer
+	assert.Nil(s.T(), s.tf.RenderSQL(&rendered, "user"))
+	assert.Contains(s.T(), rendered.String(), "INSERT INTO `user`")
+	assert.True(s.T(), strings.HasSuffix(rendered.String(), "TRUNCATE TABLE user;\n\n"))
+	assert.Equal(s.T(), 0, countTable(s.db, "user"))
+}
+
+func (s *SuiteTestFixtureTester) TestScope_RenderSQL_ClearInserted() {
+	tf := s.newFixture(ClearWith(ClearInserted))
+
+	scope := tf.Use("user")
+	defer scope.Clear()
+
+	var rendered bytes.Buffer
+	assert.Nil(s.T(), scope.RenderSQL(&rendered))
+	assert.True(s.T(), strings.HasSuffix(rendered.String(), "DELETE FROM user WHERE (`id`) IN (('1'), ('2'));\n\n"), rendered.String())
+}
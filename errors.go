@@ -1,10 +1,89 @@
 package fixture
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
 
 var (
 	ErrFixtureDataDirNotFound = errors.New("fixture data dir not found")
 	ErrSchemaFileNotFound     = errors.New("schema file not found")
 	ErrMissingDBRawURL        = errors.New("database url is not configured")
 	ErrDriverNotSupported     = errors.New("only mysql driver is officially supported")
+	ErrPoolTimeout            = errors.New("timed out waiting for a free database in the pool")
+	ErrNoSnapshot             = errors.New("no snapshot taken in the scope")
+	ErrHostNotAllowed         = errors.New("database host is not allowed")
+	ErrReadOnlyDatabase       = errors.New("database server is read only")
+	ErrMarkerTableMissing     = errors.New("marker table not found")
+	ErrTooManyRows            = errors.New("too many rows to clear")
+	ErrLockTimeout            = errors.New("timed out waiting for a lock held by another process")
 )
+
+const maxStatementLenInError = 200
+
+// OpError is returned when a statement of a database operation fails, it
+// tells the table and the statement which were running.
+type OpError struct {
+	Op        string
+	Table     string
+	Statement string
+	Err       error
+}
+
+func (e *OpError) Error() string {
+	stmt := e.Statement
+	if len(stmt) > maxStatementLenInError {
+		stmt = stmt[:maxStatementLenInError] + "..."
+	}
+
+	if e.Table == "" {
+		return fmt.Sprintf("fixture: failed to %s: %s (statement: %s)", e.Op, e.Err, stmt)
+	}
+	return fmt.Sprintf("fixture: failed to %s table '%s': %s (statement: %s)", e.Op, e.Table, e.Err, stmt)
+}
+
+func (e *OpError) Unwrap() error {
+	return e.Err
+}
+
+// Timeout tells whether the operation is aborted by a deadline.
+func (e *OpError) Timeout() bool {
+	return e.Err == context.DeadlineExceeded
+}
+
+// NotReadyError is returned when the database server doesn't accept
+// connections within the ReadyTimeout option.
+type NotReadyError struct {
+	// URL is the redacted url of the server.
+	URL      string
+	Attempts int
+	Elapsed  time.Duration
+	// Err is the error of the last attempt.
+	Err error
+}
+
+func (e *NotReadyError) Error() string {
+	return fmt.Sprintf("fixture: database '%s' not ready after %s (%d attempts): %s",
+		e.URL, e.Elapsed.Round(time.Millisecond), e.Attempts, e.Err)
+}
+
+func (e *NotReadyError) Unwrap() error {
+	return e.Err
+}
+
+// SafetyError is returned when a check of the Safety option refuses an
+// operation, Err is the check which failed.
+type SafetyError struct {
+	Err    error
+	Detail string
+}
+
+func (e *SafetyError) Error() string {
+	return fmt.Sprintf("fixture: refused, %s: %s", e.Err, e.Detail)
+}
+
+func (e *SafetyError) Unwrap() error {
+	return e.Err
+}
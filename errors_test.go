@@ -0,0 +1,24 @@
+package fixture
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpError(t *testing.T) {
+	err := &OpError{Op: "insert", Table: "user", Statement: "INSERT INTO `user` (`id`) VALUES (1)", Err: context.DeadlineExceeded}
+	assert.Equal(t, "fixture: failed to insert table 'user': context deadline exceeded (statement: INSERT INTO `user` (`id`) VALUES (1))", err.Error())
+	assert.True(t, err.Timeout())
+	assert.Equal(t, context.DeadlineExceeded, err.Unwrap())
+
+	err = &OpError{Op: "begin", Statement: "BEGIN", Err: context.Canceled}
+	assert.Equal(t, "fixture: failed to begin: context canceled (statement: BEGIN)", err.Error())
+	assert.False(t, err.Timeout())
+
+	err = &OpError{Op: "insert", Table: "user", Statement: strings.Repeat("x", 300), Err: context.Canceled}
+	assert.Contains(t, err.Error(), strings.Repeat("x", maxStatementLenInError)+"...)")
+	assert.NotContains(t, err.Error(), strings.Repeat("x", maxStatementLenInError+1))
+}
@@ -34,7 +34,9 @@ type ExportContent struct {
 	Rows    []*SortedMap `json:"rows" yaml:"rows"`
 }
 
-func genExportContent(tableName string, columns []string, rawRows [][][]byte) *ExportContent {
+// NewExportContent converts raw query results into rows keyed by column,
+// a NULL becomes nil and any other value becomes a string.
+func NewExportContent(tableName string, columns []string, rawRows [][][]byte) *ExportContent {
 	content := &ExportContent{
 		Table:   tableName,
 		Version: "1.0",
@@ -9,7 +9,7 @@ func NewJsonExporter() *JsonExporter {
 }
 
 func (exporter *JsonExporter) Export(tableName string, columns []string, rawRows [][][]byte) ([]byte, error) {
-	content := genExportContent(tableName, columns, rawRows)
+	content := NewExportContent(tableName, columns, rawRows)
 	if content == nil {
 		return nil, ErrEmptyExportContent
 	}
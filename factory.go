@@ -0,0 +1,217 @@
+package fixture
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/iFaceless/fixture/loaders"
+)
+
+// Factory builds rows of a table from default values, which are adjusted
+// by named traits and overrides for every created row.
+type Factory struct {
+	table    string
+	defaults Values
+	traits   map[string]Values
+}
+
+// FactoryTrait is a named set of values applied on top of the defaults of
+// a factory, see Trait.
+type FactoryTrait struct {
+	name   string
+	values Values
+}
+
+// Trait names a set of values for DefineFactory, such as an 'admin' trait
+// setting the role of a user.
+func Trait(name string, values Values) FactoryTrait {
+	return FactoryTrait{name: name, values: values}
+}
+
+// FactoryValue is a value of a factory computed for every created row,
+// see Sequence and Association.
+type FactoryValue interface {
+	value(ctx context.Context, s *Scope) (interface{}, error)
+}
+
+type sequence struct {
+	n int64
+	f func(n int) interface{}
+}
+
+func (seq *sequence) value(ctx context.Context, s *Scope) (interface{}, error) {
+	return seq.f(int(atomic.AddInt64(&seq.n, 1))), nil
+}
+
+// Sequence computes a value from a counter starting at 1, so that rows
+// created by a factory get unique values such as 'user1@example.com'.
+func Sequence(f func(n int) interface{}) FactoryValue {
+	return &sequence{f: f}
+}
+
+type association struct {
+	factory string
+	opts    []CreateOption
+}
+
+func (a *association) value(ctx context.Context, s *Scope) (interface{}, error) {
+	return s.CreateContext(ctx, a.factory, a.opts...)
+}
+
+// Association creates a row by another factory in the same scope and
+// takes its id, such as the user of a post.
+func Association(factory string, opts ...CreateOption) FactoryValue {
+	return &association{factory: factory, opts: opts}
+}
+
+var (
+	factoryMu  sync.RWMutex
+	factoryMap = make(map[string]*Factory)
+)
+
+// DefineFactory defines the factory of a table, redefining it replaces the
+// previous one. Values may be FactoryValue computed for every row.
+func DefineFactory(tableName string, defaults Values, traits ...FactoryTrait) *Factory {
+	factory := &Factory{
+		table:    tableName,
+		defaults: defaults,
+		traits:   make(map[string]Values, len(traits)),
+	}
+	for _, trait := range traits {
+		factory.traits[trait.name] = trait.values
+	}
+
+	factoryMu.Lock()
+	defer factoryMu.Unlock()
+	factoryMap[tableName] = factory
+	return factory
+}
+
+func lookupFactory(name string) *Factory {
+	factoryMu.RLock()
+	defer factoryMu.RUnlock()
+
+	factory, ok := factoryMap[name]
+	if !ok {
+		panic(fmt.Sprintf("factory '%s' not found", name))
+	}
+	return factory
+}
+
+// CreateOption adjusts a row created by a factory, see With and Override.
+type CreateOption interface {
+	apply(values Values, factory *Factory)
+}
+
+type withTraits []string
+
+func (w withTraits) apply(values Values, factory *Factory) {
+	for _, name := range w {
+		trait, ok := factory.traits[name]
+		if !ok {
+			panic(fmt.Sprintf("trait '%s' not found in factory '%s'", name, factory.table))
+		}
+		for col, val := range trait {
+			values[col] = val
+		}
+	}
+}
+
+// With applies traits of the factory in order.
+func With(traits ...string) CreateOption {
+	return withTraits(traits)
+}
+
+// Override sets values of the created row, taking precedence over the
+// defaults and traits even if it comes before With.
+type Override Values
+
+func (o Override) apply(values Values, factory *Factory) {
+	for col, val := range o {
+		values[col] = val
+	}
+}
+
+// Create inserts a row built by the factory of a table and returns its id,
+// which is the primary key set by the factory or else the generated one.
+// The row is cleared along with the scope. It panics on any error.
+func (s *Scope) Create(factory string, opts ...CreateOption) int64 {
+	id, err := s.CreateContext(context.Background(), factory, opts...)
+	panicOnErr(err)
+	return id
+}
+
+// CreateContext is like Create but returns the database errors.
+func (s *Scope) CreateContext(ctx context.Context, factory string, opts ...CreateOption) (int64, error) {
+	f := lookupFactory(factory)
+	tb := s.tf.lookupTable(f.table)
+	if tb == nil {
+		panic(fmt.Sprintf("table '%s' not found", f.table))
+	}
+
+	values := make(Values, len(f.defaults))
+	for col, val := range f.defaults {
+		values[col] = val
+	}
+	// traits come first whatever the order of the options, so that the
+	// overrides take precedence
+	for _, opt := range opts {
+		if _, ok := opt.(Override); !ok {
+			opt.apply(values, f)
+		}
+	}
+	for _, opt := range opts {
+		if o, ok := opt.(Override); ok {
+			o.apply(values, f)
+		}
+	}
+
+	row := make(map[string]interface{}, len(values))
+	for col, val := range values {
+		if fv, ok := val.(FactoryValue); ok {
+			v, err := fv.value(ctx, s)
+			if err != nil {
+				return 0, err
+			}
+			val = v
+		}
+		row[col] = val
+	}
+
+	result, err := s.insertContent(ctx, tb, &loaders.LoadContent{Table: tb.sqlName, Rows: []map[string]interface{}{row}})
+	if err != nil {
+		return 0, err
+	}
+
+	if len(tb.primaryKey) == 1 {
+		if id, ok := row[tb.primaryKey[0]]; ok {
+			return toInt64(id)
+		}
+	}
+
+	if result == nil {
+		return 0, nil
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, &OpError{Op: "insert", Table: tb.name, Statement: "LAST_INSERT_ID()", Err: err}
+	}
+	return id, nil
+}
+
+func toInt64(val interface{}) (int64, error) {
+	v := reflect.ValueOf(val)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint()), nil
+	case reflect.String:
+		return strconv.ParseInt(v.String(), 10, 64)
+	}
+	return 0, fmt.Errorf("id '%v' is not an integer", val)
+}
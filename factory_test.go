@@ -0,0 +1,50 @@
+package fixture
+
+import (
+	"fmt"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (s *SuiteTestFixtureTester) TestCreate() {
+	DefineFactory("user", Values{
+		"nickname": Sequence(func(n int) interface{} { return n }),
+		"phone_no": Sequence(func(n int) interface{} { return fmt.Sprintf("+86193%08d", n) }),
+		"address":  "Shanghai, China",
+	}, Trait("hangzhou", Values{"address": "Hangzhou, China"}))
+	DefineFactory("task", Values{
+		"id":          Sequence(func(n int) interface{} { return 100 + n }),
+		"user_id":     Association("user", With("hangzhou")),
+		"title":       "Write tests",
+		"description": "",
+		"checked":     false,
+	})
+
+	scope := s.tf.Use()
+	first := scope.Create("user")
+	second := scope.Create("user", With("hangzhou"), Override{"phone_no": "+8619300000000"})
+	assert.NotEqual(s.T(), first, second)
+	assert.True(s.T(), scope.Exists("user", "id = ? AND address = ?", first, "Shanghai, China"))
+	assert.True(s.T(), scope.Exists("user", "id = ? AND address = ? AND phone_no = ?", second, "Hangzhou, China", "+8619300000000"))
+
+	// overrides take precedence over traits in any order
+	third := scope.Create("user", Override{"address": "Beijing, China"}, With("hangzhou"))
+	assert.True(s.T(), scope.Exists("user", "id = ? AND address = ?", third, "Beijing, China"))
+
+	// the explicit primary key is returned, and the associated user is created first
+	task := scope.Create("task")
+	assert.Equal(s.T(), int64(101), task)
+	assert.Equal(s.T(), 4, countTable(s.db, "user"))
+	assert.True(s.T(), scope.Exists("task", "id = ? AND user_id IN (SELECT id FROM user WHERE address = ?)", task, "Hangzhou, China"))
+
+	scope.Clear()
+	assert.Equal(s.T(), 0, countTable(s.db, "user"))
+	assert.Equal(s.T(), 0, countTable(s.db, "task"))
+
+	assert.PanicsWithValue(s.T(), "factory 'missing' not found", func() {
+		s.tf.Use().Create("missing")
+	})
+	assert.PanicsWithValue(s.T(), "trait 'missing' not found in factory 'user'", func() {
+		s.tf.Use().Create("user", With("missing"))
+	})
+}
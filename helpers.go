@@ -20,22 +20,39 @@ func panicOnErr(err error) {
 	}
 }
 
-func findFixtureData(fixtureDataDir string, table *table) *fixtureData {
+// findFixtureData finds the fixture file of a table, which is named after
+// the variant as well if it's not empty, such as 'user.admin.yml'.
+func findFixtureData(fixtureDataDir string, table *table, variant string) *fixtureData {
 	if !isPathExist(fixtureDataDir) {
 		panic(ErrFixtureDataDirNotFound)
 	}
 
+	found := searchFixtureData(fixtureDataDir, table.name, variant)
+	if found == nil {
+		panic(fmt.Sprintf("fixture data not found for table '%s'", variantName(table.name, variant)))
+	}
+	return found
+}
+
+// searchFixtureData looks for the fixture file of a table and variant in
+// any format, it returns nil if there is none.
+func searchFixtureData(dir string, name string, variant string) *fixtureData {
+	baseName := name
+	if variant != "" {
+		baseName += "." + variant
+	}
+
 	possibleNames := make([]string, 0)
 	for ext := range extToDataFmtMapping {
-		possibleNames = append(possibleNames, table.name+ext)
+		possibleNames = append(possibleNames, baseName+ext)
 	}
 
 	var found string
-	for _, name := range possibleNames {
-		absPath := path.Join(fixtureDataDir, name)
+	for _, fileName := range possibleNames {
+		absPath := path.Join(dir, fileName)
 		if isPathExist(absPath) {
 			if found != "" {
-				panic(fmt.Sprintf("multiple formats of fixture data found for table '%s'", table.name))
+				panic(fmt.Sprintf("multiple formats of fixture data found for table '%s'", variantName(name, variant)))
 			}
 
 			found = absPath
@@ -43,7 +60,7 @@ func findFixtureData(fixtureDataDir string, table *table) *fixtureData {
 	}
 
 	if found == "" {
-		panic(fmt.Sprintf("fixture data not found for table '%s'", table.name))
+		return nil
 	}
 
 	return &fixtureData{
@@ -52,8 +69,17 @@ func findFixtureData(fixtureDataDir string, table *table) *fixtureData {
 	}
 }
 
+func variantName(name string, variant string) string {
+	if variant == "" {
+		return name
+	}
+	return name + tableVariantSep + variant
+}
+
 var rule = regexp.MustCompile("CREATE\\s.*TABLE\\s(.*)\\(.*")
 
+var primaryKeyRule = regexp.MustCompile(`(?i)PRIMARY\s+KEY\s*\(([^)]*(?:\([0-9]+\)[^)]*)*)\)`)
+
 func parseSchemaFile(filename string) []*table {
 	buf, err := ioutil.ReadFile(filename)
 	panicOnErr(err)
@@ -71,9 +97,11 @@ func parseSchemaFile(filename string) []*table {
 		}
 
 		tb := &table{
-			name:      trimTableName(groups[1]),
-			createSQL: createSQL,
+			name:       trimTableName(groups[1]),
+			createSQL:  createSQL,
+			primaryKey: parsePrimaryKey(createSQL),
 		}
+		tb.sqlName = tb.name
 
 		tables = append(tables, tb)
 	}
@@ -81,6 +109,33 @@ func parseSchemaFile(filename string) []*table {
 	return tables
 }
 
+// parsePrimaryKey extracts the primary key columns from a create sql,
+// prefix lengths such as 'name(10)' are dropped.
+func parsePrimaryKey(createSQL string) []string {
+	groups := primaryKeyRule.FindStringSubmatch(createSQL)
+	if len(groups) != 2 {
+		return nil
+	}
+
+	columns := make([]string, 0)
+	for _, col := range strings.Split(groups[1], ",") {
+		if i := strings.Index(col, "("); i != -1 {
+			col = col[:i]
+		}
+		columns = append(columns, trimTableName(col))
+	}
+	return columns
+}
+
+func quoteName(n string) string {
+	return "`" + strings.Replace(n, "`", "``", -1) + "`"
+}
+
+// quoteLiteral renders a string literal of MySQL.
+func quoteLiteral(v string) string {
+	return "'" + strings.NewReplacer("\\", "\\\\", "'", "''").Replace(v) + "'"
+}
+
 func trimTableName(n string) string {
 	n = strings.Replace(n, "`", "", len(n))
 	n = strings.Replace(n, "'", "", len(n))
@@ -1,6 +1,8 @@
 package fixture
 
 import (
+	"io/ioutil"
+	"os"
 	"path"
 	"runtime"
 	"testing"
@@ -27,13 +29,21 @@ func Test_parseSchemaFile(t *testing.T) {
 	assert.Equal(t, "foo", ret[2].name)
 }
 
+func Test_parsePrimaryKey(t *testing.T) {
+	ret := parseSchemaFile(path.Join(testDataDir, "schema.sql"))
+	assert.Equal(t, []string{"id"}, ret[0].primaryKey)
+
+	assert.Equal(t, []string{"group_id", "name"}, parsePrimaryKey("CREATE TABLE t (\n  PRIMARY KEY (`group_id`, `name`(10)),\n  KEY `idx` (`name`)\n)"))
+	assert.Nil(t, parsePrimaryKey("CREATE TABLE t (`id` int)"))
+}
+
 func Test_findFixtureData_YAML_OK(t *testing.T) {
 	targetTable := &table{name: "user"}
 	expectedResult := &fixtureData{
 		Path:   path.Join(fixtureDataDir, "user.yml"),
 		Format: YAML,
 	}
-	ret := findFixtureData(fixtureDataDir, targetTable)
+	ret := findFixtureData(fixtureDataDir, targetTable, "")
 	assert.Equal(t, expectedResult, ret)
 }
 
@@ -43,7 +53,7 @@ func Test_findFixtureData_JSON_OK(t *testing.T) {
 		Path:   path.Join(fixtureDataDir, "foo.json"),
 		Format: JSON,
 	}
-	ret := findFixtureData(fixtureDataDir, targetTable)
+	ret := findFixtureData(fixtureDataDir, targetTable, "")
 	assert.Equal(t, expectedResult, ret)
 }
 
@@ -53,21 +63,57 @@ func Test_findFixtureData_SQL_OK(t *testing.T) {
 		Path:   path.Join(fixtureDataDir, "bar.sql"),
 		Format: SQL,
 	}
-	ret := findFixtureData(fixtureDataDir, targetTable)
+	ret := findFixtureData(fixtureDataDir, targetTable, "")
 	assert.Equal(t, expectedResult, ret)
 }
 
 func Test_findFixtureData_Panics(t *testing.T) {
 	assert.PanicsWithValue(t, "multiple formats of fixture data found for table 'beep'", func() {
-		findFixtureData(fixtureDataDir, &table{name: "beep"})
+		findFixtureData(fixtureDataDir, &table{name: "beep"}, "")
 	})
 
 	assert.PanicsWithValue(t, "fixture data not found for table 'hidden'", func() {
-		findFixtureData(fixtureDataDir, &table{name: "hidden"})
+		findFixtureData(fixtureDataDir, &table{name: "hidden"}, "")
 	})
 }
 
+func Test_findFixtureData_Variant(t *testing.T) {
+	expectedResult := &fixtureData{
+		Path:   path.Join(fixtureDataDir, "user.admin.yml"),
+		Format: YAML,
+	}
+	assert.Equal(t, expectedResult, findFixtureData(fixtureDataDir, &table{name: "user"}, "admin"))
+
+	assert.PanicsWithValue(t, "multiple formats of fixture data found for table 'user@conflict'", func() {
+		findFixtureData(fixtureDataDir, &table{name: "user"}, "conflict")
+	})
+
+	assert.PanicsWithValue(t, "fixture data not found for table 'user@hidden'", func() {
+		findFixtureData(fixtureDataDir, &table{name: "user"}, "hidden")
+	})
+}
+
+func Test_splitTableVariant(t *testing.T) {
+	name, variant := splitTableVariant("user@admin")
+	assert.Equal(t, "user", name)
+	assert.Equal(t, "admin", variant)
+
+	name, variant = splitTableVariant("user")
+	assert.Equal(t, "user", name)
+	assert.Equal(t, "", variant)
+}
+
 func Test_isPathExist(t *testing.T) {
 	assert.True(t, isPathExist(fixtureDataDir))
 	assert.False(t, isPathExist(fixtureDataDir+"/file-not-found.txt"))
 }
+
+// tempDir is like t.TempDir, which is missing before Go 1.15.
+func tempDir(t testing.TB) string {
+	dir, err := ioutil.TempDir("", "fixture")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
@@ -0,0 +1,134 @@
+package fixture
+
+import (
+	"context"
+	"fmt"
+)
+
+// HookEvent tells when a hook runs.
+type HookEvent int
+
+const (
+	// BeforeLoad runs before any fixture data of a scope is inserted.
+	BeforeLoad HookEvent = iota
+	// AfterLoadTable runs after the fixture data of a table is inserted.
+	AfterLoadTable
+	// AfterLoad runs after all fixture data of a scope is inserted, just
+	// before it's committed.
+	AfterLoad
+	// BeforeClear runs before a scope is cleared.
+	BeforeClear
+	// AfterClear runs after a scope is cleared.
+	AfterClear
+)
+
+func (e HookEvent) String() string {
+	switch e {
+	case BeforeLoad:
+		return "BeforeLoad"
+	case AfterLoadTable:
+		return "AfterLoadTable"
+	case AfterLoad:
+		return "AfterLoad"
+	case BeforeClear:
+		return "BeforeClear"
+	case AfterClear:
+		return "AfterClear"
+	default:
+		return fmt.Sprintf("HookEvent(%d)", int(e))
+	}
+}
+
+// HookContext is what a hook gets to know about the operation.
+type HookContext struct {
+	Context context.Context
+	Event   HookEvent
+	Scope   *Scope
+	// Tables are the selected tables of the scope.
+	Tables []string
+	// Table and FixturePath are only set for AfterLoadTable.
+	Table       string
+	FixturePath string
+	// Tx runs statements within the operation, it's the loading
+	// transaction for load events.
+	Tx DBTX
+}
+
+// Hook runs custom logic around loading and clearing, an error aborts
+// the operation.
+type Hook func(hc *HookContext) error
+
+// HookError is returned when a hook aborts an operation.
+type HookError struct {
+	Event HookEvent
+	Table string
+	Err   error
+}
+
+func (e *HookError) Error() string {
+	if e.Table == "" {
+		return fmt.Sprintf("fixture: %s hook failed: %s", e.Event, e.Err)
+	}
+	return fmt.Sprintf("fixture: %s hook failed for table '%s': %s", e.Event, e.Table, e.Err)
+}
+
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// AddHook registers a hook for every scope of the fixture.
+func (tf *TestFixture) AddHook(event HookEvent, hook Hook) {
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
+
+	if tf.hooks == nil {
+		tf.hooks = make(map[HookEvent][]Hook)
+	}
+	tf.hooks[event] = append(tf.hooks[event], hook)
+}
+
+// AddHook registers a hook for the scope and its child scopes. As the
+// scope is loaded already, load hooks only apply to its child scopes.
+func (s *Scope) AddHook(event HookEvent, hook Hook) {
+	if s.hooks == nil {
+		s.hooks = make(map[HookEvent][]Hook)
+	}
+	s.hooks[event] = append(s.hooks[event], hook)
+}
+
+// runHooks runs the hooks of the fixture first, then the ones of the
+// ancestors of the scope from the root down, then its own.
+func (s *Scope) runHooks(hc *HookContext) error {
+	s.tf.mu.Lock()
+	hooks := append([]Hook(nil), s.tf.hooks[hc.Event]...)
+	s.tf.mu.Unlock()
+
+	scopes := make([]*Scope, 0)
+	for scope := s; scope != nil; scope = scope.parent {
+		scopes = append([]*Scope{scope}, scopes...)
+	}
+	for _, scope := range scopes {
+		hooks = append(hooks, scope.hooks[hc.Event]...)
+	}
+
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	hc.Scope = s
+	hc.Tables = s.tableNames()
+	for _, hook := range hooks {
+		if err := hook(hc); err != nil {
+			return &HookError{Event: hc.Event, Table: hc.Table, Err: err}
+		}
+	}
+	return nil
+}
+
+func (s *Scope) tableNames() []string {
+	names := make([]string, 0, len(s.selectedTables))
+	for _, tb := range s.selectedTables {
+		names = append(names, tb.name)
+	}
+	return names
+}
@@ -0,0 +1,87 @@
+package fixture
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHookError(t *testing.T) {
+	err := &HookError{Event: AfterLoadTable, Table: "user", Err: errors.New("boom")}
+	assert.Equal(t, "fixture: AfterLoadTable hook failed for table 'user': boom", err.Error())
+
+	err = &HookError{Event: BeforeClear, Err: errors.New("boom")}
+	assert.Equal(t, "fixture: BeforeClear hook failed: boom", err.Error())
+	assert.Equal(t, "boom", err.Unwrap().Error())
+	assert.Equal(t, "HookEvent(10)", HookEvent(10).String())
+}
+
+func (s *SuiteTestFixtureTester) TestHooks() {
+	tf := s.newFixture()
+
+	events := make([]string, 0)
+	record := func(hc *HookContext) error {
+		events = append(events, hc.Event.String()+":"+hc.Table)
+		return nil
+	}
+	for _, event := range []HookEvent{BeforeLoad, AfterLoadTable, AfterLoad, BeforeClear, AfterClear} {
+		tf.AddHook(event, record)
+	}
+
+	tf.AddHook(AfterLoadTable, func(hc *HookContext) error {
+		assert.Equal(s.T(), path.Join(fixtureDataDir, hc.Table+".yml"), hc.FixturePath)
+		assert.Equal(s.T(), 2, countTable(hc.Tx, hc.Table))
+		return nil
+	})
+
+	scope := tf.Use("user")
+	scope.AddHook(AfterClear, func(hc *HookContext) error {
+		assert.Equal(s.T(), []string{"user"}, hc.Tables)
+		assert.Equal(s.T(), 0, countTable(hc.Tx, "user"))
+		return nil
+	})
+	scope.Clear()
+
+	assert.Equal(s.T(), []string{
+		"BeforeLoad:",
+		"AfterLoadTable:user",
+		"AfterLoad:",
+		"BeforeClear:",
+		"AfterClear:",
+	}, events)
+}
+
+func (s *SuiteTestFixtureTester) TestHooks_Abort() {
+	tf := s.newFixture()
+
+	boom := errors.New("boom")
+	tf.AddHook(AfterLoadTable, func(hc *HookContext) error {
+		return boom
+	})
+
+	_, err := tf.UseContext(context.Background(), "user")
+	assert.Equal(s.T(), &HookError{Event: AfterLoadTable, Table: "user", Err: boom}, err)
+	assert.Equal(s.T(), 0, countTable(s.db, "user"))
+
+	scope := s.tf.Use("user")
+	scope.AddHook(BeforeClear, func(hc *HookContext) error {
+		return boom
+	})
+	assert.Equal(s.T(), &HookError{Event: BeforeClear, Err: boom}, scope.ClearContext(context.Background()))
+	assert.Equal(s.T(), 2, countTable(s.db, "user"))
+
+	// Clear logs the error
+	var buf bytes.Buffer
+	defer WithLogger(s.tf.config.Logger)(s.tf)
+	WithLogger(NewLogger(&buf, LevelInfo))(s.tf)
+	scope.Clear()
+	assert.Contains(s.T(), buf.String(), `fixture: ERROR failed to clear scope tables=user error="fixture: BeforeClear hook failed: boom"`)
+
+	scope.hooks = nil
+	scope.Clear()
+	assert.Equal(s.T(), 0, countTable(s.db, "user"))
+}
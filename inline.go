@@ -0,0 +1,80 @@
+package fixture
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/iFaceless/fixture/loaders"
+)
+
+// Values is a row of a table given inline, keyed by column.
+type Values map[string]interface{}
+
+// Insert inserts rows into a table without a fixture file, values are
+// rendered the same way as the ones of fixture files. The table joins the
+// tables selected by the scope if it's not selected yet, so the rows are
+// cleared along with the scope. It panics on any error.
+func (s *Scope) Insert(tableName string, rows ...Values) *Scope {
+	panicOnErr(s.InsertContext(context.Background(), tableName, rows...))
+	return s
+}
+
+// InsertContext is like Insert but returns the database errors.
+func (s *Scope) InsertContext(ctx context.Context, tableName string, rows ...Values) error {
+	tb := s.tf.lookupTable(tableName)
+	if tb == nil {
+		panic(fmt.Sprintf("table '%s' not found", tableName))
+	}
+
+	content := &loaders.LoadContent{Table: tb.sqlName}
+	for _, row := range rows {
+		content.Rows = append(content.Rows, map[string]interface{}(row))
+	}
+	_, err := s.insertContent(ctx, tb, content)
+	return err
+}
+
+// insertContent inserts the rows into the table with the connection of
+// the scope, tracking them for the ClearInserted strategy. The result is
+// nil if there are no rows or under the DryRun option.
+func (s *Scope) insertContent(ctx context.Context, tb *table, content *loaders.LoadContent) (sql.Result, error) {
+	sqlStr := loaders.GenSQL(content)
+	if sqlStr == "" {
+		return nil, nil
+	}
+
+	if !s.selectsTable(tb) {
+		if s.tf.config.DryRun == nil {
+			if err := s.lockTable(ctx, tb); err != nil {
+				return nil, err
+			}
+		}
+		s.selectedTables = append(s.selectedTables, tb)
+	}
+
+	if s.tf.config.DryRun != nil {
+		s.recordRows(tb, content.Rows)
+		return nil, writeStatements(s.tf.config.DryRun, []string{sqlStr})
+	}
+
+	db := s.DB()
+	var result sql.Result
+	insert := func() (err error) {
+		result, err = s.tf.exec(ctx, db, "insert", tb.name, sqlStr)
+		return err
+	}
+
+	var err error
+	if s.tx == nil && s.clearStrategy(tb) == ClearInserted {
+		err = s.trackInserted(ctx, db, tb, insert)
+	} else {
+		err = insert()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordRows(tb, content.Rows)
+	return result, nil
+}
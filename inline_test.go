@@ -0,0 +1,38 @@
+package fixture
+
+import (
+	"github.com/stretchr/testify/assert"
+)
+
+func (s *SuiteTestFixtureTester) TestInsert() {
+	scope := s.tf.Use("user")
+	scope.Insert("user", Values{"_label": "tom", "id": 3, "nickname": 3, "phone_no": "+8619300000000", "address": "O'Brien \\ street"})
+	assert.Equal(s.T(), 3, countTable(s.db, "user"))
+	assert.True(s.T(), scope.Exists("user", "id = ? AND address = ?", 3, "O'Brien \\ street"))
+	assert.Equal(s.T(), 3, scope.FixtureRow("user", "tom")["id"])
+
+	// tables not selected yet are cleared along with the scope
+	scope.Insert("foo", Values{"id": 10}, Values{"id": 11})
+	assert.Equal(s.T(), 2, scope.Count("foo", ""))
+
+	scope.Clear()
+	assert.Equal(s.T(), 0, countTable(s.db, "user"))
+	assert.Equal(s.T(), 0, countTable(s.db, "foo"))
+
+	assert.PanicsWithValue(s.T(), "table 'missing_table' not found", func() {
+		s.tf.Use().Insert("missing_table", Values{"id": 1})
+	})
+}
+
+func (s *SuiteTestFixtureTester) TestInsert_ClearInserted() {
+	_, err := s.db.Exec("INSERT INTO foo (id) VALUES (99)")
+	assert.Nil(s.T(), err)
+	defer s.db.Exec("TRUNCATE TABLE foo")
+
+	scope := s.tf.Use().ClearTableWith("foo", ClearInserted)
+	scope.Insert("foo", Values{"id": 10})
+	assert.Equal(s.T(), 2, countTable(s.db, "foo"))
+
+	scope.Clear()
+	assert.Equal(s.T(), 1, countTable(s.db, "foo"))
+}
@@ -10,6 +10,13 @@ type Loader interface {
 	Load(filename string) (string, error)
 }
 
+// ContentLoader is a Loader which can also parse a fixture file into rows,
+// it's required to assert tables against the fixture files of a format.
+type ContentLoader interface {
+	Loader
+	Parse(filename string) (*loaders.LoadContent, error)
+}
+
 var loaderMap = make(map[DataFormat]Loader)
 
 func LookupLoader(dataFmt DataFormat) Loader {
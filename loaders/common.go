@@ -1,10 +1,17 @@
 package loaders
 
 import (
+	"database/sql/driver"
 	"fmt"
+	"reflect"
 	"strings"
+	"time"
 )
 
+// LabelKey is the reserved column naming a row, so that tests can refer to
+// the row by it. It's never inserted.
+const LabelKey = "_label"
+
 type LoadContent struct {
 	Version string                   `yaml:"version" json:"version"`
 	Table   string                   `yaml:"table" json:"table"`
@@ -16,7 +23,9 @@ VALUES
  %s;
 `
 
-func genSQL(content *LoadContent) string {
+// GenSQL renders the rows of content as an INSERT statement, it's empty if
+// there are no rows.
+func GenSQL(content *LoadContent) string {
 	if content == nil || content.Table == "" {
 		return ""
 	}
@@ -27,7 +36,9 @@ func genSQL(content *LoadContent) string {
 
 	columns := make([]string, 0)
 	for k := range content.Rows[0] {
-		columns = append(columns, k)
+		if k != LabelKey {
+			columns = append(columns, k)
+		}
 	}
 
 	vals := make([]string, 0)
@@ -35,11 +46,7 @@ func genSQL(content *LoadContent) string {
 		fields := make([]string, 0)
 		for _, col := range columns {
 			if val, ok := row[col]; ok {
-				if val == nil || val == interface{}(nil) {
-					fields = append(fields, "NULL")
-				} else {
-					fields = append(fields, fmt.Sprintf("'%v'", val))
-				}
+				fields = append(fields, RenderValue(val))
 			} else {
 				panic(fmt.Sprintf("fixture.loaders: incosistent column found '%s'", col))
 			}
@@ -56,6 +63,54 @@ func genSQL(content *LoadContent) string {
 	return exp
 }
 
+// RenderValue renders a value of a row as a sql literal. Nil is NULL,
+// numbers are left as they are, bools are 1 or 0, times are formatted
+// as 'YYYY-MM-DD hh:mm:ss' and everything else is quoted as a string.
+// Pointers and driver.Valuer values are rendered by what they hold.
+func RenderValue(val interface{}) string {
+	if valuer, ok := val.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			panic(fmt.Sprintf("fixture.loaders: failed to render value '%v': %s", val, err))
+		}
+		val = v
+	}
+
+	switch v := val.(type) {
+	case nil:
+		return "NULL"
+	case time.Time:
+		return quoteString(v.Format("2006-01-02 15:04:05.999999"))
+	case []byte:
+		return quoteString(string(v))
+	}
+
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return "NULL"
+		}
+		return RenderValue(rv.Elem().Interface())
+	case reflect.Bool:
+		if rv.Bool() {
+			return "1"
+		}
+		return "0"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return fmt.Sprint(val)
+	}
+	return quoteString(fmt.Sprint(val))
+}
+
+func quoteString(s string) string {
+	s = strings.Replace(s, "\\", "\\\\", -1)
+	s = strings.Replace(s, "'", "''", -1)
+	return "'" + s + "'"
+}
+
 func quote(col string) string {
 	if col != "" {
 		col = fmt.Sprintf("`%s`", col)
@@ -0,0 +1,42 @@
+package loaders
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderValue(t *testing.T) {
+	name := "Kary"
+	var missing *string
+
+	assert.Equal(t, "NULL", RenderValue(nil))
+	assert.Equal(t, "NULL", RenderValue(missing))
+	assert.Equal(t, "'Kary'", RenderValue(&name))
+	assert.Equal(t, "1", RenderValue(true))
+	assert.Equal(t, "0", RenderValue(false))
+	assert.Equal(t, "42", RenderValue(uint8(42)))
+	assert.Equal(t, "1.5", RenderValue(1.5))
+	assert.Equal(t, "'1'", RenderValue("1"))
+	assert.Equal(t, "'O''Brien \\\\ street'", RenderValue("O'Brien \\ street"))
+	assert.Equal(t, "'raw'", RenderValue([]byte("raw")))
+	assert.Equal(t, "'2019-01-02 15:04:05'", RenderValue(time.Date(2019, 1, 2, 15, 4, 5, 0, time.UTC)))
+	assert.Equal(t, "'2019-01-02 15:04:05.5'", RenderValue(time.Date(2019, 1, 2, 15, 4, 5, 5e8, time.UTC)))
+	assert.Equal(t, "NULL", RenderValue(sql.NullString{}))
+	assert.Equal(t, "'Jack'", RenderValue(sql.NullString{String: "Jack", Valid: true}))
+}
+
+func TestGenSQL(t *testing.T) {
+	content := &LoadContent{
+		Table: "user",
+		Rows:  []map[string]interface{}{{LabelKey: "kary", "id": 1, "vip": true, "address": "O'Brien \\ street"}},
+	}
+	sqlStr := GenSQL(content)
+	assert.Contains(t, sqlStr, "INSERT INTO `user`")
+	assert.NotContains(t, sqlStr, "kary")
+	for _, val := range []string{"1", "'O''Brien \\\\ street'"} {
+		assert.Contains(t, sqlStr, val)
+	}
+}
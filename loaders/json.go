@@ -13,16 +13,27 @@ func NewJsonLoader() *JsonLoader {
 }
 
 func (loader *JsonLoader) Load(filename string) (string, error) {
-	file, err := os.Open(filename)
+	content, err := loader.Parse(filename)
 	if err != nil {
 		return "", err
 	}
 
+	return GenSQL(content), nil
+}
+
+// Parse reads the rows of a fixture file without rendering them as sql.
+func (loader *JsonLoader) Parse(filename string) (*LoadContent, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
 	var content LoadContent
 	err = json.NewDecoder(file).Decode(&content)
 	if err != nil {
-		panic(fmt.Sprintf("failed to load file '%s': %s", filename, err))
+		return nil, fmt.Errorf("failed to load file '%s': %s", filename, err)
 	}
 
-	return genSQL(&content), nil
+	return &content, nil
 }
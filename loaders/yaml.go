@@ -14,17 +14,27 @@ func NewYamlLoader() *YamlLoader {
 }
 
 func (loader *YamlLoader) Load(filename string) (string, error) {
-	buf, err := ioutil.ReadFile(filename)
+	content, err := loader.Parse(filename)
 	if err != nil {
 		return "", err
 	}
 
+	return GenSQL(content), nil
+}
+
+// Parse reads the rows of a fixture file without rendering them as sql.
+func (loader *YamlLoader) Parse(filename string) (*LoadContent, error) {
+	buf, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
 	var content LoadContent
 
 	err = yaml.Unmarshal(buf, &content)
 	if err != nil {
-		panic(fmt.Sprintf("failed to load file '%s': %s", filename, err))
+		return nil, fmt.Errorf("failed to load file '%s': %s", filename, err)
 	}
 
-	return genSQL(&content), nil
+	return &content, nil
 }
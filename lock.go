@@ -0,0 +1,280 @@
+package fixture
+
+import (
+	"context"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	lockNamePrefix = "fixture:"
+	// maxLockNameLen is the longest name accepted by GET_LOCK of MySQL.
+	maxLockNameLen = 64
+)
+
+// processLock is a set of named locks taken by a scope or an operation of
+// the fixture.
+type processLock struct {
+	names []string
+}
+
+// heldLock is a named lock held by the fixture on a dedicated connection.
+// Scopes of the fixture share it, so it's acquired when the first of them
+// takes it and released when the last of them lets it go.
+type heldLock struct {
+	// mu is held while the lock is acquired or released.
+	mu   sync.Mutex
+	conn *sql.Conn
+	refs int
+}
+
+// ProcessLock makes the fixture hold named locks of the database server
+// while it creates or drops tables and during the lifetime of every scope,
+// so test binaries of concurrent packages sharing a test database don't
+// clobber each other. It waits at most timeout for a lock, a non-positive
+// timeout waits forever. Every lock holds a connection of the pool.
+func ProcessLock(timeout time.Duration) Option {
+	return func(tf *TestFixture) {
+		tf.config.ProcessLock = true
+		tf.config.LockTimeout = timeout
+	}
+}
+
+// TableLocks makes the ProcessLock option lock tables one by one instead
+// of the whole database, so scopes of disjoint tables run concurrently.
+// Tables joining a scope by Insert, Create or InsertStructs are locked
+// when they join.
+func TableLocks() Option {
+	return func(tf *TestFixture) {
+		tf.config.TableLocks = true
+	}
+}
+
+// lockNames names the locks guarding the tables of a database.
+func (tf *TestFixture) lockNames(dbURL *DatabaseURL, tables []*table) []string {
+	dbName := tf.sharedDBName
+	if dbURL != nil {
+		dbName = dbURL.DBName()
+	}
+
+	if !tf.config.TableLocks {
+		return []string{lockName(dbName)}
+	}
+
+	names := make([]string, 0, len(tables))
+	for _, tb := range tables {
+		names = append(names, lockName(dbName+"."+tb.sqlName))
+	}
+	// a fixed order keeps two scopes from waiting for each other
+	sort.Strings(names)
+	return names
+}
+
+func lockName(name string) string {
+	name = lockNamePrefix + name
+	if len(name) <= maxLockNameLen {
+		return name
+	}
+
+	sum := sha1.Sum([]byte(name))
+	return lockNamePrefix + hex.EncodeToString(sum[:])
+}
+
+// lock acquires the named locks of the database, it returns nil if the
+// ProcessLock option is disabled or there is nothing to lock.
+func (tf *TestFixture) lock(ctx context.Context, dbURL *DatabaseURL, names []string) (*processLock, error) {
+	if !tf.config.ProcessLock || len(names) == 0 {
+		return nil, nil
+	}
+
+	l := &processLock{}
+	for _, name := range names {
+		if err := tf.holdLock(ctx, dbURL, name); err != nil {
+			tf.unlock(l)
+			return nil, err
+		}
+		l.names = append(l.names, name)
+	}
+	return l, nil
+}
+
+// holdLock takes a reference of the named lock, and acquires it from the
+// database server if the fixture doesn't hold it yet.
+func (tf *TestFixture) holdLock(ctx context.Context, dbURL *DatabaseURL, name string) error {
+	hl := tf.heldLock(name)
+	hl.mu.Lock()
+	defer hl.mu.Unlock()
+
+	if hl.refs > 0 {
+		hl.refs++
+		return nil
+	}
+
+	conn, err := tf.dbFor(dbURL).Conn(ctx)
+	if err != nil {
+		return &OpError{Op: "lock", Statement: "GET_LOCK", Err: err}
+	}
+	if err := tf.acquireLock(ctx, conn, name); err != nil {
+		conn.Close()
+		return err
+	}
+
+	hl.conn = conn
+	hl.refs = 1
+	return nil
+}
+
+func (tf *TestFixture) heldLock(name string) *heldLock {
+	tf.mu.Lock()
+	defer tf.mu.Unlock()
+
+	if tf.heldLocks == nil {
+		tf.heldLocks = make(map[string]*heldLock)
+	}
+	hl, ok := tf.heldLocks[name]
+	if !ok {
+		hl = &heldLock{}
+		tf.heldLocks[name] = hl
+	}
+	return hl
+}
+
+func (tf *TestFixture) acquireLock(ctx context.Context, conn *sql.Conn, name string) error {
+	acquired, err := getLock(ctx, conn, name, 0)
+	if err != nil {
+		return err
+	}
+	if acquired {
+		tf.logger().Debug("lock acquired", "lock", name)
+		return nil
+	}
+
+	timeout := -1
+	if tf.config.LockTimeout > 0 {
+		timeout = int(math.Ceil(tf.config.LockTimeout.Seconds()))
+	}
+
+	tf.logger().Info("waiting for lock held by another process", "lock", name, "timeout", tf.config.LockTimeout)
+	start := time.Now()
+	acquired, err = getLock(ctx, conn, name, timeout)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return &OpError{
+			Op:        "lock",
+			Statement: fmt.Sprintf("SELECT GET_LOCK('%s', %d)", name, timeout),
+			Err:       ErrLockTimeout,
+		}
+	}
+
+	tf.logger().Info("lock acquired", "lock", name, "waited", time.Since(start))
+	return nil
+}
+
+func getLock(ctx context.Context, conn *sql.Conn, name string, timeout int) (bool, error) {
+	var result sql.NullInt64
+	err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", name, timeout).Scan(&result)
+	if err != nil {
+		return false, &OpError{
+			Op:        "lock",
+			Statement: fmt.Sprintf("SELECT GET_LOCK('%s', %d)", name, timeout),
+			Err:       err,
+		}
+	}
+	return result.Valid && result.Int64 == 1, nil
+}
+
+// unlock lets the locks go.
+func (tf *TestFixture) unlock(l *processLock) {
+	if l == nil {
+		return
+	}
+
+	for _, name := range l.names {
+		tf.releaseLock(name)
+	}
+	l.names = nil
+}
+
+// releaseLock drops a reference of the named lock, and releases it along
+// with the connection holding it when that's the last one.
+func (tf *TestFixture) releaseLock(name string) {
+	hl := tf.heldLock(name)
+	hl.mu.Lock()
+	defer hl.mu.Unlock()
+
+	if hl.refs == 0 {
+		return
+	}
+	hl.refs--
+	if hl.refs > 0 {
+		return
+	}
+
+	var result sql.NullInt64
+	err := hl.conn.QueryRowContext(context.Background(), "SELECT RELEASE_LOCK(?)", name).Scan(&result)
+	if err != nil {
+		tf.logger().Error("failed to release lock", "lock", name, "error", err)
+		discardConn(hl.conn)
+	} else {
+		hl.conn.Close()
+	}
+	hl.conn = nil
+}
+
+// lockNames names the locks the scope has to acquire, which exclude the
+// ones held by its ancestors.
+func (s *Scope) lockNames() []string {
+	if !s.tf.config.TableLocks {
+		if s.parent != nil {
+			return nil
+		}
+		return s.tf.lockNames(s.url, nil)
+	}
+
+	tables := make([]*table, 0, len(s.selectedTables))
+	for _, tb := range s.selectedTables {
+		if !s.parent.selectsTable(tb) {
+			tables = append(tables, tb)
+		}
+	}
+	return s.tf.lockNames(s.url, tables)
+}
+
+// lockTable acquires the lock of a table joining the scope after it's
+// created, such as by Insert, along with the other locks of the scope.
+func (s *Scope) lockTable(ctx context.Context, tb *table) error {
+	if !s.tf.config.ProcessLock || !s.tf.config.TableLocks {
+		return nil
+	}
+
+	names := s.tf.lockNames(s.url, []*table{tb})
+	if err := s.tf.holdLock(ctx, s.url, names[0]); err != nil {
+		return err
+	}
+	if s.lock == nil {
+		s.lock = &processLock{}
+	}
+	s.lock.names = append(s.lock.names, names[0])
+	return nil
+}
+
+// selectsTable tells whether the scope or any of its ancestors selects
+// the table, it's false for a nil scope.
+func (s *Scope) selectsTable(tb *table) bool {
+	for scope := s; scope != nil; scope = scope.parent {
+		for _, selected := range scope.selectedTables {
+			if selected == tb {
+				return true
+			}
+		}
+	}
+	return false
+}
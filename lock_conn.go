@@ -0,0 +1,16 @@
+//go:build go1.17
+// +build go1.17
+
+package fixture
+
+import (
+	"database/sql"
+	"database/sql/driver"
+)
+
+// discardConn closes the connection instead of returning it to the pool,
+// the server releases the locks of a closed session.
+func discardConn(conn *sql.Conn) {
+	conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	conn.Close()
+}
@@ -0,0 +1,17 @@
+//go:build !go1.17
+// +build !go1.17
+
+package fixture
+
+import (
+	"context"
+	"database/sql"
+)
+
+// discardConn releases all the locks of the session before returning the
+// connection to the pool, it can't be discarded before Go 1.17.
+func discardConn(conn *sql.Conn) {
+	var count sql.NullInt64
+	conn.QueryRowContext(context.Background(), "SELECT RELEASE_ALL_LOCKS()").Scan(&count)
+	conn.Close()
+}
@@ -0,0 +1,126 @@
+package fixture
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_lockName(t *testing.T) {
+	assert.Equal(t, "fixture:test_fixture", lockName("test_fixture"))
+
+	long := lockName("test_fixture." + strings.Repeat("x", 64))
+	assert.True(t, strings.HasPrefix(long, "fixture:"))
+	assert.True(t, len(long) <= maxLockNameLen)
+}
+
+func Test_lockNames(t *testing.T) {
+	url, err := Parse("mysql://root@localhost/test_fixture")
+	assert.Nil(t, err)
+	tables := []*table{{name: "user", sqlName: "user"}, {name: "foo", sqlName: "foo"}}
+
+	tf := &TestFixture{config: &Config{}}
+	assert.Equal(t, []string{"fixture:test_fixture"}, tf.lockNames(url, tables))
+
+	tf.config.TableLocks = true
+	assert.Equal(t, []string{"fixture:test_fixture.foo", "fixture:test_fixture.user"}, tf.lockNames(url, tables))
+}
+
+func (s *SuiteTestFixtureTester) holdLock(name string) func() {
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	assert.Nil(s.T(), err)
+
+	var acquired int
+	assert.Nil(s.T(), conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 0)", name).Scan(&acquired))
+	assert.Equal(s.T(), 1, acquired)
+
+	return func() {
+		conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", name)
+		conn.Close()
+	}
+}
+
+func (s *SuiteTestFixtureTester) isLockFree(name string) bool {
+	var free int
+	assert.Nil(s.T(), s.db.QueryRow("SELECT IS_FREE_LOCK(?)", name).Scan(&free))
+	return free == 1
+}
+
+func (s *SuiteTestFixtureTester) TestProcessLock() {
+	tf := s.newFixture(ProcessLock(time.Second))
+	name := lockName(tf.config.DatabaseURL.DBName())
+
+	release := s.holdLock(name)
+	_, err := tf.UseContext(context.Background(), "user")
+	assert.True(s.T(), errors.Is(err, ErrLockTimeout), "%v", err)
+	assert.Equal(s.T(), 0, countTable(s.db, "user"))
+	release()
+
+	scope := tf.Use("user")
+	child := scope.Use("foo")
+	assert.False(s.T(), s.isLockFree(name))
+
+	child.Clear()
+	assert.False(s.T(), s.isLockFree(name))
+	scope.Clear()
+	assert.True(s.T(), s.isLockFree(name))
+}
+
+func (s *SuiteTestFixtureTester) TestProcessLock_SiblingScopes() {
+	tf := s.newFixture(ProcessLock(time.Second))
+	name := lockName(tf.config.DatabaseURL.DBName())
+
+	// the lock of the fixture is shared by its scopes
+	user, err := tf.UseContext(context.Background(), "user")
+	assert.Nil(s.T(), err)
+	foo, err := tf.UseContext(context.Background(), "foo")
+	assert.Nil(s.T(), err)
+	assert.Equal(s.T(), 2, countTable(s.db, "user"))
+
+	user.Clear()
+	assert.False(s.T(), s.isLockFree(name))
+	foo.Clear()
+	assert.True(s.T(), s.isLockFree(name))
+}
+
+func (s *SuiteTestFixtureTester) TestProcessLock_TableLocks() {
+	tf := s.newFixture(ProcessLock(time.Second), TableLocks())
+	dbName := tf.config.DatabaseURL.DBName()
+
+	release := s.holdLock(lockName(dbName + ".user"))
+	defer release()
+
+	scope := tf.Use("foo")
+	assert.False(s.T(), s.isLockFree(lockName(dbName+".foo")))
+	scope.Clear()
+	assert.True(s.T(), s.isLockFree(lockName(dbName+".foo")))
+
+	_, err := tf.UseContext(context.Background(), "user")
+	assert.True(s.T(), errors.Is(err, ErrLockTimeout), "%v", err)
+}
+
+func (s *SuiteTestFixtureTester) TestProcessLock_TableLocksOfInsertedTables() {
+	tf := s.newFixture(ProcessLock(time.Second), TableLocks())
+	dbName := tf.config.DatabaseURL.DBName()
+
+	// tables joining the scope are locked as well
+	scope := tf.Use("foo")
+	scope.Insert("bar", Values{"id": 10})
+	assert.False(s.T(), s.isLockFree(lockName(dbName+".bar")))
+	scope.Clear()
+	assert.True(s.T(), s.isLockFree(lockName(dbName+".bar")))
+
+	release := s.holdLock(lockName(dbName + ".bar"))
+	defer release()
+
+	scope = tf.Use()
+	err := scope.InsertContext(context.Background(), "bar", Values{"id": 10})
+	assert.True(s.T(), errors.Is(err, ErrLockTimeout), "%v", err)
+	assert.Equal(s.T(), 0, countTable(s.db, "bar"))
+	scope.Clear()
+}
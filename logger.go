@@ -0,0 +1,131 @@
+package fixture
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+// Logger receives the structured logs of the fixture, keyvals are pairs
+// of keys and values such as "table", "user". A *slog.Logger satisfies
+// it as is.
+type Logger interface {
+	Debug(msg string, keyvals ...interface{})
+	Info(msg string, keyvals ...interface{})
+	Warn(msg string, keyvals ...interface{})
+	Error(msg string, keyvals ...interface{})
+}
+
+// Level is the severity of a log.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	default:
+		return "ERROR"
+	}
+}
+
+var defaultLogger = NewLogger(os.Stderr, LevelInfo)
+
+// NewLogger returns a Logger writing the logs at or above level to w in
+// the format of the standard log package.
+func NewLogger(w io.Writer, level Level) Logger {
+	return &stdLogger{logger: log.New(w, "", log.LstdFlags), level: level}
+}
+
+// NopLogger returns a Logger which discards everything.
+func NopLogger() Logger {
+	return nopLogger{}
+}
+
+// TestingLogger returns a Logger writing to the log of a test, which is
+// only shown if the test fails or runs with -v.
+func TestingLogger(tb testing.TB) Logger {
+	return &testingLogger{tb: tb}
+}
+
+type stdLogger struct {
+	logger *log.Logger
+	level  Level
+}
+
+func (l *stdLogger) Debug(msg string, keyvals ...interface{}) { l.log(LevelDebug, msg, keyvals) }
+func (l *stdLogger) Info(msg string, keyvals ...interface{})  { l.log(LevelInfo, msg, keyvals) }
+func (l *stdLogger) Warn(msg string, keyvals ...interface{})  { l.log(LevelWarn, msg, keyvals) }
+func (l *stdLogger) Error(msg string, keyvals ...interface{}) { l.log(LevelError, msg, keyvals) }
+
+func (l *stdLogger) log(level Level, msg string, keyvals []interface{}) {
+	if level < l.level {
+		return
+	}
+	l.logger.Print(formatLog(level, msg, keyvals))
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(msg string, keyvals ...interface{}) {}
+func (nopLogger) Info(msg string, keyvals ...interface{})  {}
+func (nopLogger) Warn(msg string, keyvals ...interface{})  {}
+func (nopLogger) Error(msg string, keyvals ...interface{}) {}
+
+type testingLogger struct {
+	tb testing.TB
+}
+
+func (l *testingLogger) Debug(msg string, keyvals ...interface{}) { l.log(LevelDebug, msg, keyvals) }
+func (l *testingLogger) Info(msg string, keyvals ...interface{})  { l.log(LevelInfo, msg, keyvals) }
+func (l *testingLogger) Warn(msg string, keyvals ...interface{})  { l.log(LevelWarn, msg, keyvals) }
+func (l *testingLogger) Error(msg string, keyvals ...interface{}) { l.log(LevelError, msg, keyvals) }
+
+func (l *testingLogger) log(level Level, msg string, keyvals []interface{}) {
+	l.tb.Helper()
+	l.tb.Log(formatLog(level, msg, keyvals))
+}
+
+// formatLog renders a log as 'fixture: LEVEL msg key=value ...'.
+func formatLog(level Level, msg string, keyvals []interface{}) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "fixture: %s %s", level, msg)
+	for i := 0; i < len(keyvals); i += 2 {
+		if i+1 < len(keyvals) {
+			fmt.Fprintf(&b, " %v=%v", keyvals[i], formatLogValue(keyvals[i+1]))
+		} else {
+			fmt.Fprintf(&b, " %v=<missing>", keyvals[i])
+		}
+	}
+	return b.String()
+}
+
+func formatLogValue(v interface{}) string {
+	str := fmt.Sprint(v)
+	if strings.ContainsAny(str, " \t\n\"=") {
+		return fmt.Sprintf("%q", str)
+	}
+	return str
+}
+
+// logger returns the configured Logger, falling back to the default one
+// writing to stderr.
+func (tf *TestFixture) logger() Logger {
+	if tf.config.Logger == nil {
+		return defaultLogger
+	}
+	return tf.config.Logger
+}
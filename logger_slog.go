@@ -0,0 +1,14 @@
+//go:build go1.21
+// +build go1.21
+
+package fixture
+
+import "log/slog"
+
+// SlogLogger adapts a *slog.Logger, nil means slog.Default().
+func SlogLogger(l *slog.Logger) Logger {
+	if l == nil {
+		l = slog.Default()
+	}
+	return l
+}
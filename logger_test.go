@@ -0,0 +1,40 @@
+package fixture
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf, LevelInfo)
+
+	logger.Debug("hidden", "table", "user")
+	assert.Equal(t, "", buf.String())
+
+	logger.Info("insert fixture data", "table", "user", "file", "testdata/user.yml", "rows", 2, "duration", time.Second)
+	assert.Contains(t, buf.String(), "fixture: INFO insert fixture data table=user file=testdata/user.yml rows=2 duration=1s\n")
+
+	buf.Reset()
+	logger.Error("failed to clear table", "table", "user", "error", "lock wait timeout")
+	assert.Contains(t, buf.String(), `fixture: ERROR failed to clear table table=user error="lock wait timeout"`)
+
+	buf.Reset()
+	logger.Warn("odd", "key")
+	assert.Contains(t, buf.String(), "fixture: WARN odd key=<missing>")
+}
+
+func TestLoggers(t *testing.T) {
+	NopLogger().Error("discarded")
+	TestingLogger(t).Info("logged by testing", "table", "user")
+
+	tf := &TestFixture{config: new(Config)}
+	assert.Equal(t, defaultLogger, tf.logger())
+
+	logger := NopLogger()
+	WithLogger(logger)(tf)
+	assert.Equal(t, logger, tf.logger())
+}
@@ -0,0 +1,55 @@
+package fixture
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// dbPool hands out clones of the test database, so scopes of parallel
+// tests never share tables with each other.
+type dbPool struct {
+	urls    []*DatabaseURL
+	free    chan *DatabaseURL
+	timeout time.Duration
+}
+
+func newDBPool(base *DatabaseURL, size int, timeout time.Duration) *dbPool {
+	pool := &dbPool{
+		free:    make(chan *DatabaseURL, size),
+		timeout: timeout,
+	}
+
+	for i := 1; i <= size; i++ {
+		cloneURL, err := base.WithDBName(fmt.Sprintf("%s_%d", base.DBName(), i))
+		panicOnErr(err)
+
+		pool.urls = append(pool.urls, cloneURL)
+		pool.free <- cloneURL
+	}
+	return pool
+}
+
+// acquire blocks until a clone is free or ctx is done, a non-positive
+// timeout waits forever.
+func (pool *dbPool) acquire(ctx context.Context) (*DatabaseURL, error) {
+	var timeout <-chan time.Time
+	if pool.timeout > 0 {
+		timer := time.NewTimer(pool.timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case dbURL := <-pool.free:
+		return dbURL, nil
+	case <-timeout:
+		return nil, ErrPoolTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (pool *dbPool) release(dbURL *DatabaseURL) {
+	pool.free <- dbURL
+}
@@ -0,0 +1,176 @@
+package fixture
+
+import (
+	"regexp"
+	"strings"
+)
+
+// tableRefRule matches the table names following the keywords which
+// reference a table in a statement.
+var tableRefRule = regexp.MustCompile("(?i)\\b(TABLE(?:\\s+IF\\s+(?:NOT\\s+)?EXISTS)?|INTO|FROM|UPDATE|JOIN|REFERENCES|LIKE)(\\s+)(`?)(\\w+)(`?)")
+
+// constraintRule matches the names of the constraints defined by a
+// statement, which are unique in a database for foreign keys of InnoDB.
+var constraintRule = regexp.MustCompile("(?i)\\b(CONSTRAINT)(\\s+)(`?)(\\w+)(`?)")
+
+// TablePrefix prefixes the names of all the tables in the database, such
+// as 'pkgfoo__user' for the table 'user' of the schema, so that packages
+// can share one test database safely. Named constraints of the schema are
+// prefixed as well. Fixture files, scenarios and the
+// APIs keep using the names of the schema, the tables referenced by SQL
+// fixture files and scenarios are rewritten, except in string literals and
+// comments. Statements run by hooks and code under test aren't rewritten,
+// TableName maps the tables to the names in the database for them.
+func TablePrefix(prefix string) Option {
+	return func(tf *TestFixture) {
+		tf.config.TablePrefix = prefix
+	}
+}
+
+// TableName returns the name of a table of the schema in the database,
+// which comes with the TablePrefix option. Unknown names are returned as
+// they are.
+func (tf *TestFixture) TableName(name string) string {
+	if tb := tf.lookupTable(name); tb != nil {
+		return tb.sqlName
+	}
+	return name
+}
+
+// TableName is like TestFixture.TableName, for hooks and tests which only
+// get the scope.
+func (s *Scope) TableName(name string) string {
+	return s.tf.TableName(name)
+}
+
+// TableNameMapping maps the tables of the schema to their names in the
+// database.
+func (tf *TestFixture) TableNameMapping() map[string]string {
+	mapping := make(map[string]string, len(tf.tables))
+	for _, tb := range tf.tables {
+		mapping[tb.name] = tb.sqlName
+	}
+	return mapping
+}
+
+// applyTablePrefix renames the tables of the schema by the TablePrefix
+// option, including the tables referenced by foreign keys and the named
+// constraints.
+func (tf *TestFixture) applyTablePrefix() {
+	if tf.config.TablePrefix == "" {
+		return
+	}
+
+	for _, tb := range tf.tables {
+		tb.sqlName = tf.config.TablePrefix + tb.name
+	}
+	for _, tb := range tf.tables {
+		tb.createSQL = rewriteCode(tf.prefixTableNames(tb.createSQL), tf.prefixConstraints)
+	}
+}
+
+// prefixTableNames rewrites the tables of the schema referenced by a
+// statement into their names in the database, string literals and
+// comments are left alone.
+func (tf *TestFixture) prefixTableNames(query string) string {
+	if tf.config.TablePrefix == "" {
+		return query
+	}
+
+	return rewriteCode(query, tf.prefixTableRefs)
+}
+
+// rewriteCode rewrites a query except its string literals and comments.
+func rewriteCode(query string, rewrite func(code string) string) string {
+	var b strings.Builder
+	for len(query) > 0 {
+		end := skipLiteral(query)
+		if end > 0 {
+			b.WriteString(query[:end])
+			query = query[end:]
+			continue
+		}
+
+		end = nextLiteral(query)
+		b.WriteString(rewrite(query[:end]))
+		query = query[end:]
+	}
+	return b.String()
+}
+
+func (tf *TestFixture) prefixTableRefs(code string) string {
+	return tableRefRule.ReplaceAllStringFunc(code, func(ref string) string {
+		groups := tableRefRule.FindStringSubmatch(ref)
+		tb := tf.lookupTable(groups[4])
+		if tb == nil || groups[3] != groups[5] {
+			return ref
+		}
+		return groups[1] + groups[2] + groups[3] + tb.sqlName + groups[5]
+	})
+}
+
+func (tf *TestFixture) prefixConstraints(code string) string {
+	return constraintRule.ReplaceAllStringFunc(code, func(ref string) string {
+		groups := constraintRule.FindStringSubmatch(ref)
+		if groups[3] != groups[5] || (groups[3] == "" && isConstraintKeyword(groups[4])) {
+			return ref
+		}
+		return groups[1] + groups[2] + groups[3] + tf.config.TablePrefix + groups[4] + groups[5]
+	})
+}
+
+// isConstraintKeyword tells whether a word following CONSTRAINT starts the
+// constraint rather than names it.
+func isConstraintKeyword(word string) bool {
+	switch strings.ToUpper(word) {
+	case "PRIMARY", "UNIQUE", "FOREIGN", "CHECK":
+		return true
+	}
+	return false
+}
+
+// nextLiteral returns the offset of the first string literal or comment
+// of a query, or its length if there is none.
+func nextLiteral(query string) int {
+	for i := 0; i < len(query); i++ {
+		if skipLiteral(query[i:]) > 0 {
+			return i
+		}
+	}
+	return len(query)
+}
+
+// skipLiteral returns the length of the string literal or comment at the
+// start of a query, or 0 if it doesn't start with one. An unterminated
+// one lasts to the end of the query.
+func skipLiteral(query string) int {
+	switch {
+	case strings.HasPrefix(query, "'"), strings.HasPrefix(query, "\""):
+		quote := query[0]
+		for i := 1; i < len(query); i++ {
+			switch query[i] {
+			case '\\':
+				i++
+			case quote:
+				// a doubled quote is an escaped one
+				if i+1 < len(query) && query[i+1] == quote {
+					i++
+					continue
+				}
+				return i + 1
+			}
+		}
+		return len(query)
+	case strings.HasPrefix(query, "-- "), strings.HasPrefix(query, "#"):
+		if i := strings.IndexByte(query, '\n'); i != -1 {
+			return i + 1
+		}
+		return len(query)
+	case strings.HasPrefix(query, "/*"):
+		if i := strings.Index(query[2:], "*/"); i != -1 {
+			return i + 4
+		}
+		return len(query)
+	}
+	return 0
+}
@@ -0,0 +1,66 @@
+package fixture
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_applyTablePrefix(t *testing.T) {
+	tf := &TestFixture{
+		config: &Config{TablePrefix: "pkg__"},
+		tables: []*table{
+			{name: "user", sqlName: "user", createSQL: "CREATE TABLE `user` (`id` int, `updated_at` timestamp ON UPDATE CURRENT_TIMESTAMP)"},
+			{name: "task", sqlName: "task", createSQL: "CREATE TABLE task (`user_id` int, FOREIGN KEY (`user_id`) REFERENCES `user` (`id`))"},
+			{name: "post", sqlName: "post", createSQL: "CREATE TABLE post (`user_id` int, `note` varchar(64) DEFAULT 'CONSTRAINT x', CONSTRAINT `fk_post_user` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`), CONSTRAINT PRIMARY KEY (`user_id`))"},
+		},
+	}
+	tf.applyTablePrefix()
+
+	assert.Equal(t, "CREATE TABLE `pkg__user` (`id` int, `updated_at` timestamp ON UPDATE CURRENT_TIMESTAMP)", tf.tables[0].createSQL)
+	assert.Equal(t, "CREATE TABLE pkg__task (`user_id` int, FOREIGN KEY (`user_id`) REFERENCES `pkg__user` (`id`))", tf.tables[1].createSQL)
+	// named constraints are unique in a database
+	assert.Equal(t, "CREATE TABLE pkg__post (`user_id` int, `note` varchar(64) DEFAULT 'CONSTRAINT x', CONSTRAINT `pkg__fk_post_user` FOREIGN KEY (`user_id`) REFERENCES `pkg__user` (`id`), CONSTRAINT PRIMARY KEY (`user_id`))", tf.tables[2].createSQL)
+	assert.Equal(t, map[string]string{"user": "pkg__user", "task": "pkg__task", "post": "pkg__post"}, tf.TableNameMapping())
+	assert.Equal(t, "pkg__user", tf.TableName("user"))
+	assert.Equal(t, "other", tf.TableName("other"))
+
+	assert.Equal(t,
+		"INSERT INTO `pkg__user` (`id`) VALUES (1); UPDATE pkg__task SET user_id = 1; INSERT INTO `other` VALUES (1)",
+		tf.prefixTableNames("INSERT INTO `user` (`id`) VALUES (1); UPDATE task SET user_id = 1; INSERT INTO `other` VALUES (1)"),
+	)
+	assert.Equal(t, "SELECT * FROM `user", tf.prefixTableNames("SELECT * FROM `user"))
+
+	// string literals and comments are left alone
+	assert.Equal(t,
+		"INSERT INTO pkg__user (note) VALUES ('moved from user table', 'it''s from user', \"\\\" from user\");\n-- copied from user\n/* into user */ UPDATE pkg__task SET note = '# from user'",
+		tf.prefixTableNames("INSERT INTO user (note) VALUES ('moved from user table', 'it''s from user', \"\\\" from user\");\n-- copied from user\n/* into user */ UPDATE task SET note = '# from user'"),
+	)
+	assert.Equal(t, "SELECT 'from user", tf.prefixTableNames("SELECT 'from user"))
+}
+
+func (s *SuiteTestFixtureTester) TestTablePrefix() {
+	tf := s.newFixture(TablePrefix("pkgfoo__"))
+	assert.Equal(s.T(), "pkgfoo__user", tf.TableNameMapping()["user"])
+
+	for _, name := range tf.TableNames() {
+		assert.True(s.T(), isTableExistInDB(s.db, "pkgfoo__"+name))
+	}
+
+	scope := tf.Use("user", "bar")
+	assert.Equal(s.T(), 2, countTable(s.db, "pkgfoo__user"))
+	assert.Equal(s.T(), 2, countTable(s.db, "pkgfoo__bar"))
+	assert.Equal(s.T(), 2, scope.Count("user", ""))
+	// tables of the other namespace are left alone
+	assert.Equal(s.T(), 0, countTable(s.db, "user"))
+
+	scope.Clear()
+	assert.Equal(s.T(), 0, countTable(s.db, "pkgfoo__user"))
+	assert.Equal(s.T(), 0, countTable(s.db, "pkgfoo__bar"))
+
+	tf.DropTables()
+	for _, name := range tf.TableNames() {
+		assert.False(s.T(), isTableExistInDB(s.db, "pkgfoo__"+name))
+		assert.True(s.T(), isTableExistInDB(s.db, name))
+	}
+}
@@ -0,0 +1,256 @@
+package fixture
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// AllowAnyTable lets the query helpers of the scope read tables it
+// doesn't select.
+func (s *Scope) AllowAnyTable() *Scope {
+	s.anyTable = true
+	return s
+}
+
+// Count counts the rows of a table matching where, an empty where counts
+// all rows. Like the other query helpers it reads through Scope.DB, only
+// from the tables selected by the scope or its ancestors, within the
+// Timeout option, and panics on any error.
+func (s *Scope) Count(tableName, where string, args ...interface{}) int {
+	var count int
+	query := s.selectSQL("COUNT(*)", tableName, where)
+	ctx, cancel := s.tf.withTimeout(context.Background())
+	defer cancel()
+
+	err := s.DB().QueryRowContext(ctx, query, args...).Scan(&count)
+	if err != nil {
+		panic(queryError(ctx, tableName, query, err))
+	}
+	return count
+}
+
+// Exists tells whether any row of a table matches where.
+func (s *Scope) Exists(tableName, where string, args ...interface{}) bool {
+	return s.Count(tableName, where, args...) > 0
+}
+
+// Rows fetches the rows of a table matching where, keyed by column. Text
+// values come back as strings and NULL as nil.
+func (s *Scope) Rows(tableName, where string, args ...interface{}) []map[string]interface{} {
+	query := s.selectSQL("*", tableName, where)
+	ctx, cancel := s.tf.withTimeout(context.Background())
+	defer cancel()
+
+	rows, err := s.DB().QueryContext(ctx, query, args...)
+	if err != nil {
+		panic(queryError(ctx, tableName, query, err))
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		panic(queryError(ctx, tableName, query, err))
+	}
+
+	results := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			panic(queryError(ctx, tableName, query, err))
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, col := range columns {
+			if buf, ok := values[i].([]byte); ok {
+				row[col] = string(buf)
+			} else {
+				row[col] = values[i]
+			}
+		}
+		results = append(results, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		panic(queryError(ctx, tableName, query, err))
+	}
+	return results
+}
+
+// ScanInto fetches the rows of a table matching where into dest, which
+// is a pointer to a slice of structs or of struct pointers. Columns map
+// to fields by the 'db' tag, the 'column' of the 'gorm' tag or else by the
+// snake case of the field name, columns without a field are skipped.
+func (s *Scope) ScanInto(tableName, where string, dest interface{}, args ...interface{}) {
+	sliceVal := reflect.ValueOf(dest)
+	if sliceVal.Kind() != reflect.Ptr || sliceVal.Elem().Kind() != reflect.Slice {
+		panic(fmt.Sprintf("fixture: ScanInto expects a pointer to a slice, got %T", dest))
+	}
+	sliceVal = sliceVal.Elem()
+
+	elemType := sliceVal.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("fixture: ScanInto expects a slice of structs, got %T", dest))
+	}
+	fields := structColumns(structType)
+
+	query := s.selectSQL("*", tableName, where)
+	ctx, cancel := s.tf.withTimeout(context.Background())
+	defer cancel()
+
+	rows, err := s.DB().QueryContext(ctx, query, args...)
+	if err != nil {
+		panic(queryError(ctx, tableName, query, err))
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		panic(queryError(ctx, tableName, query, err))
+	}
+
+	for rows.Next() {
+		elem := reflect.New(structType).Elem()
+		dest := make([]interface{}, len(columns))
+		for i, col := range columns {
+			if index, ok := fields[col]; ok {
+				dest[i] = elem.FieldByIndex(index).Addr().Interface()
+			} else {
+				dest[i] = new(interface{})
+			}
+		}
+		if err := rows.Scan(dest...); err != nil {
+			panic(queryError(ctx, tableName, query, err))
+		}
+
+		if elemType.Kind() == reflect.Ptr {
+			sliceVal.Set(reflect.Append(sliceVal, elem.Addr()))
+		} else {
+			sliceVal.Set(reflect.Append(sliceVal, elem))
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		panic(queryError(ctx, tableName, query, err))
+	}
+}
+
+func (s *Scope) selectSQL(columns, tableName, where string) string {
+	s.checkQueryable(tableName)
+
+	query := fmt.Sprintf("SELECT %s FROM %s", columns, s.tf.TableName(tableName))
+	if where != "" {
+		query += " WHERE " + where
+	}
+	return query
+}
+
+// checkQueryable panics unless the table is selected by the scope or its
+// ancestors, or AllowAnyTable is set.
+func (s *Scope) checkQueryable(tableName string) {
+	if s.anyTable {
+		return
+	}
+
+	for scope := s; scope != nil; scope = scope.parent {
+		for _, tb := range scope.selectedTables {
+			if tb.name == tableName {
+				return
+			}
+		}
+	}
+	panic(fmt.Sprintf("table '%s' is not selected by the scope", tableName))
+}
+
+// queryError wraps an error of the query helpers.
+func queryError(ctx context.Context, tableName, query string, err error) *OpError {
+	// the driver may report a canceled query as a broken connection
+	if ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	return &OpError{Op: "query", Table: tableName, Statement: query, Err: err}
+}
+
+// structColumns maps column names to the field indexes of a struct,
+// fields of embedded structs are promoted.
+func structColumns(t reflect.Type) map[string][]int {
+	columns := make(map[string][]int)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		name := columnName(field)
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			for col, index := range structColumns(field.Type) {
+				if _, ok := columns[col]; !ok {
+					columns[col] = append([]int{i}, index...)
+				}
+			}
+			continue
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = toSnakeCase(field.Name)
+		}
+		columns[name] = []int{i}
+	}
+	return columns
+}
+
+// columnName reads the column of a field from its 'db' or 'gorm' tag, it's
+// empty if the field isn't tagged.
+func columnName(field reflect.StructField) string {
+	if tag, ok := field.Tag.Lookup("db"); ok {
+		return strings.Split(tag, ",")[0]
+	}
+	if tag, ok := field.Tag.Lookup("gorm"); ok {
+		for _, setting := range strings.Split(tag, ";") {
+			setting = strings.TrimSpace(setting)
+			if setting == "-" {
+				return "-"
+			}
+			if strings.HasPrefix(setting, "column:") {
+				return strings.TrimPrefix(setting, "column:")
+			}
+		}
+	}
+	return ""
+}
+
+// toSnakeCase converts a field name such as 'PhoneNo' or 'UserID' into
+// 'phone_no' or 'user_id'.
+func toSnakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			prevLower := i > 0 && unicode.IsLower(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if i > 0 && (prevLower || (nextLower && unicode.IsUpper(runes[i-1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
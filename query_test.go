@@ -0,0 +1,94 @@
+package fixture
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type queryUser struct {
+	ID      int64  `db:"id"`
+	PhoneNo string `db:"phone_no"`
+	Address string
+	Ignored string `db:"-"`
+}
+
+func Test_structColumns(t *testing.T) {
+	type base struct {
+		CreatedAt string
+	}
+	type user struct {
+		base
+		UserID   int64
+		Nickname string `db:"nick_name,omitempty"`
+		PhoneNo  string `gorm:"type:varchar(64);column:mobile"`
+		Skipped  string `gorm:"-"`
+		internal string
+	}
+
+	assert.Equal(t, map[string][]int{
+		"created_at": {0, 0},
+		"user_id":    {1},
+		"nick_name":  {2},
+		"mobile":     {3},
+	}, structColumns(reflect.TypeOf(user{})))
+}
+
+func Test_toSnakeCase(t *testing.T) {
+	assert.Equal(t, "phone_no", toSnakeCase("PhoneNo"))
+	assert.Equal(t, "user_id", toSnakeCase("UserID"))
+	assert.Equal(t, "html_parser", toSnakeCase("HTMLParser"))
+	assert.Equal(t, "id", toSnakeCase("ID"))
+}
+
+func (s *SuiteTestFixtureTester) TestQueryHelpers() {
+	scope := s.tf.Use("user")
+	defer scope.Clear()
+
+	assert.Equal(s.T(), 2, scope.Count("user", ""))
+	assert.Equal(s.T(), 1, scope.Count("user", "id > ?", 1))
+	assert.True(s.T(), scope.Exists("user", "phone_no = ?", "+8619393992882"))
+	assert.False(s.T(), scope.Exists("user", "id = ?", 3))
+
+	rows := scope.Rows("user", "id = ?", 1)
+	if assert.Equal(s.T(), 1, len(rows)) {
+		assert.Equal(s.T(), "Beijing, China", rows[0]["address"])
+	}
+
+	var users []queryUser
+	scope.ScanInto("user", "", &users)
+	assert.Equal(s.T(), []queryUser{
+		{ID: 1, PhoneNo: "+8619393992882", Address: "Beijing, China"},
+		{ID: 2, PhoneNo: "+8619303992122", Address: "Shanghai, China"},
+	}, users)
+
+	var userPtrs []*queryUser
+	scope.ScanInto("user", "id = ?", &userPtrs, 2)
+	if assert.Equal(s.T(), 1, len(userPtrs)) {
+		assert.Equal(s.T(), int64(2), userPtrs[0].ID)
+	}
+
+	assert.PanicsWithValue(s.T(), "table 'foo' is not selected by the scope", func() {
+		scope.Count("foo", "")
+	})
+	assert.Equal(s.T(), 0, scope.AllowAnyTable().Count("foo", ""))
+}
+
+func (s *SuiteTestFixtureTester) TestCount_Timeout() {
+	tf := s.newFixture()
+
+	scope := tf.Use()
+	// expires before the query is sent, so nothing hangs on the server
+	tf.config.Timeout = time.Nanosecond
+
+	defer func() {
+		err, ok := recover().(*OpError)
+		if assert.True(s.T(), ok) {
+			assert.Equal(s.T(), "SELECT COUNT(*) FROM user", err.Statement)
+			assert.True(s.T(), err.Timeout(), "%v", err)
+		}
+	}()
+	scope.AllowAnyTable().Count("user", "")
+}
@@ -0,0 +1,78 @@
+package fixture
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const (
+	initialReadyBackoff = 100 * time.Millisecond
+	maxReadyBackoff     = 2 * time.Second
+)
+
+// waitReady pings the database server until it accepts connections or
+// the ReadyTimeout option expires. The server is pinged without selecting
+// a database, which may not be created yet.
+func (tf *TestFixture) waitReady() error {
+	db, url, err := tf.serverDB()
+	if err != nil {
+		return err
+	}
+	if db != tf.config.DB {
+		defer db.Close()
+	}
+
+	start := time.Now()
+	deadline := start.Add(tf.config.ReadyTimeout)
+	backoff := initialReadyBackoff
+	for attempts := 1; ; attempts++ {
+		ctx, cancel := context.WithDeadline(context.Background(), deadline)
+		err := db.PingContext(ctx)
+		cancel()
+		if err == nil {
+			if attempts > 1 {
+				tf.logger().Info("database ready", "url", url, "attempts", attempts, "duration", time.Since(start))
+			}
+			return nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return &NotReadyError{URL: url, Attempts: attempts, Elapsed: time.Since(start), Err: err}
+		}
+
+		if backoff > remaining {
+			backoff = remaining
+		}
+		tf.logger().Warn("database not ready", "url", url, "attempt", attempts, "retry_in", backoff, "error", err)
+		time.Sleep(backoff)
+
+		backoff *= 2
+		if backoff > maxReadyBackoff {
+			backoff = maxReadyBackoff
+		}
+	}
+}
+
+// serverDB returns the handle to ping for readiness and the url to report,
+// it's the shared handle if there is one.
+func (tf *TestFixture) serverDB() (*sql.DB, string, error) {
+	if tf.config.DB != nil {
+		if tf.config.DatabaseURL == nil {
+			return tf.config.DB, "<shared db>", nil
+		}
+		return tf.config.DB, tf.config.DatabaseURL.Redacted(), nil
+	}
+
+	serverURL, err := tf.config.DatabaseURL.WithDBName("")
+	if err != nil {
+		return nil, "", err
+	}
+
+	db, err := sql.Open(serverURL.Driver(), serverURL.DSN())
+	if err != nil {
+		return nil, "", err
+	}
+	return db, serverURL.Redacted(), nil
+}
@@ -0,0 +1,12 @@
+//go:build go1.18
+// +build go1.18
+
+package fixture
+
+// Row decodes the loaded row of a table with the label or primary key
+// into a T, which must be a struct. See Scope.DecodeRow.
+func Row[T any](s *Scope, tableName, key string) T {
+	var row T
+	s.DecodeRow(tableName, key, &row)
+	return row
+}
@@ -0,0 +1,14 @@
+//go:build go1.18
+// +build go1.18
+
+package fixture
+
+import "github.com/stretchr/testify/assert"
+
+func (s *SuiteTestFixtureTester) TestRow() {
+	scope := s.tf.Use("user")
+	defer scope.Clear()
+
+	user := Row[queryUser](scope, "user", "kary")
+	assert.Equal(s.T(), queryUser{ID: 1, PhoneNo: "+8619393992882", Address: "Beijing, China"}, user)
+}
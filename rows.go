@@ -0,0 +1,247 @@
+package fixture
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iFaceless/fixture/loaders"
+)
+
+// loadedTable keeps the rows parsed from the fixture file of a table.
+type loadedTable struct {
+	table *table
+	rows  []map[string]interface{}
+}
+
+var timeLayouts = []string{
+	"2006-01-02 15:04:05",
+	time.RFC3339Nano,
+	"2006-01-02",
+}
+
+func (s *Scope) recordRows(tb *table, rows []map[string]interface{}) {
+	if s.loadedTables == nil {
+		s.loadedTables = make(map[string]*loadedTable)
+	}
+
+	if loaded, ok := s.loadedTables[tb.name]; ok {
+		loaded.rows = append(loaded.rows, rows...)
+		return
+	}
+	s.loadedTables[tb.name] = &loadedTable{table: tb, rows: rows}
+}
+
+// lookupLoaded finds the rows of a table loaded by the scope, or else by
+// its closest ancestor.
+func (s *Scope) lookupLoaded(tableName string) *loadedTable {
+	for scope := s; scope != nil; scope = scope.parent {
+		if loaded, ok := scope.loadedTables[tableName]; ok {
+			return loaded
+		}
+	}
+	return nil
+}
+
+// FixtureRows returns the rows loaded into a table from its fixture file,
+// by the scope or else by its closest ancestor. It's nil if the table
+// isn't loaded or its fixture file can't be parsed into rows, as is the
+// case for sql files.
+func (s *Scope) FixtureRows(tableName string) []map[string]interface{} {
+	loaded := s.lookupLoaded(tableName)
+	if loaded == nil {
+		return nil
+	}
+
+	rows := make([]map[string]interface{}, 0, len(loaded.rows))
+	for _, row := range loaded.rows {
+		rows = append(rows, copyRow(row))
+	}
+	return rows
+}
+
+// FixtureRow returns the loaded row of a table with the label or else the
+// primary key, values of a composite primary key are joined by commas. It
+// panics if no row matches.
+func (s *Scope) FixtureRow(tableName, key string) map[string]interface{} {
+	loaded := s.lookupLoaded(tableName)
+	if loaded != nil {
+		for _, row := range loaded.rows {
+			if label, ok := row[loaders.LabelKey]; ok && fmt.Sprint(label) == key {
+				return copyRow(row)
+			}
+		}
+
+		if len(loaded.table.primaryKey) > 0 {
+			for _, row := range loaded.rows {
+				if primaryKeyOf(loaded.table, row) == key {
+					return copyRow(row)
+				}
+			}
+		}
+	}
+	panic(fmt.Sprintf("fixture row '%s' not found in table '%s'", key, tableName))
+}
+
+// DecodeRow decodes the loaded row of a table with the label or primary
+// key into dest, a pointer to a struct. Columns map to fields the same way
+// as in ScanInto.
+func (s *Scope) DecodeRow(tableName, key string, dest interface{}) {
+	row := s.FixtureRow(tableName, key)
+	if err := decodeRow(row, dest); err != nil {
+		panic(fmt.Sprintf("failed to decode fixture row '%s' of table '%s': %s", key, tableName, err))
+	}
+}
+
+func primaryKeyOf(tb *table, row map[string]interface{}) string {
+	values := make([]string, 0, len(tb.primaryKey))
+	for _, col := range tb.primaryKey {
+		values = append(values, fmt.Sprint(row[col]))
+	}
+	return strings.Join(values, ",")
+}
+
+func copyRow(row map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(row))
+	for col, val := range row {
+		if col != loaders.LabelKey {
+			result[col] = val
+		}
+	}
+	return result
+}
+
+func decodeRow(row map[string]interface{}, dest interface{}) error {
+	destVal := reflect.ValueOf(dest)
+	if destVal.Kind() != reflect.Ptr || destVal.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("expect a pointer to a struct, got %T", dest)
+	}
+	destVal = destVal.Elem()
+
+	for col, index := range structColumns(destVal.Type()) {
+		val, ok := row[col]
+		if !ok {
+			continue
+		}
+
+		if err := assignValue(destVal.FieldByIndex(index), val); err != nil {
+			return fmt.Errorf("column '%s': %s", col, err)
+		}
+	}
+	return nil
+}
+
+// assignValue sets a field to a value parsed from a fixture file, which
+// is a string, a number, a bool, a time or nil.
+func assignValue(field reflect.Value, val interface{}) error {
+	if val == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+
+	if field.Kind() == reflect.Ptr {
+		elem := reflect.New(field.Type().Elem())
+		if err := assignValue(elem.Elem(), val); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+
+	v := reflect.ValueOf(val)
+	if v.Type().AssignableTo(field.Type()) {
+		field.Set(v)
+		return nil
+	}
+
+	if field.Type() == reflect.TypeOf(time.Time{}) {
+		if str, ok := val.(string); ok {
+			for _, layout := range timeLayouts {
+				if t, err := time.ParseInLocation(layout, str, time.Local); err == nil {
+					field.Set(reflect.ValueOf(t))
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("cannot decode %T '%v' into time", val, val)
+	}
+
+	str, isString := val.(string)
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if isNumber(v.Kind()) {
+			field.SetInt(v.Convert(reflect.TypeOf(int64(0))).Int())
+			return nil
+		}
+		if isString {
+			n, err := strconv.ParseInt(str, 10, 64)
+			if err != nil {
+				return err
+			}
+			field.SetInt(n)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if isNumber(v.Kind()) {
+			field.SetUint(v.Convert(reflect.TypeOf(uint64(0))).Uint())
+			return nil
+		}
+		if isString {
+			n, err := strconv.ParseUint(str, 10, 64)
+			if err != nil {
+				return err
+			}
+			field.SetUint(n)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		if isNumber(v.Kind()) {
+			field.SetFloat(v.Convert(reflect.TypeOf(float64(0))).Float())
+			return nil
+		}
+		if isString {
+			n, err := strconv.ParseFloat(str, 64)
+			if err != nil {
+				return err
+			}
+			field.SetFloat(n)
+			return nil
+		}
+	case reflect.Bool:
+		if isNumber(v.Kind()) {
+			field.SetBool(v.Convert(reflect.TypeOf(float64(0))).Float() != 0)
+			return nil
+		}
+		if isString {
+			b, err := strconv.ParseBool(str)
+			if err != nil {
+				return err
+			}
+			field.SetBool(b)
+			return nil
+		}
+	case reflect.String:
+		if isNumber(v.Kind()) || v.Kind() == reflect.Bool || isString {
+			field.SetString(fmt.Sprint(val))
+			return nil
+		}
+	case reflect.Slice:
+		if isString && field.Type().Elem().Kind() == reflect.Uint8 {
+			field.SetBytes([]byte(str))
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot decode %T '%v' into %s", val, val, field.Type())
+}
+
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package fixture
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fixtureUser struct {
+	ID        int64 `db:"id"`
+	Nickname  string
+	PhoneNo   *string
+	Active    bool
+	Score     float64
+	CreatedAt time.Time
+	Avatar    []byte
+}
+
+func Test_decodeRow(t *testing.T) {
+	var user fixtureUser
+	err := decodeRow(map[string]interface{}{
+		"id":         "1",
+		"nickname":   1024,
+		"phone_no":   "+8619393992882",
+		"active":     1,
+		"score":      "9.5",
+		"created_at": "2019-01-02 15:04:05",
+		"avatar":     "png",
+		"unknown":    "ignored",
+	}, &user)
+	assert.Nil(t, err)
+
+	phoneNo := "+8619393992882"
+	assert.Equal(t, fixtureUser{
+		ID:        1,
+		Nickname:  "1024",
+		PhoneNo:   &phoneNo,
+		Active:    true,
+		Score:     9.5,
+		CreatedAt: time.Date(2019, 1, 2, 15, 4, 5, 0, time.Local),
+		Avatar:    []byte("png"),
+	}, user)
+
+	err = decodeRow(map[string]interface{}{"phone_no": nil, "id": 2.0}, &user)
+	assert.Nil(t, err)
+	assert.Nil(t, user.PhoneNo)
+	assert.Equal(t, int64(2), user.ID)
+
+	err = decodeRow(map[string]interface{}{"id": "abc"}, &user)
+	assert.Contains(t, err.Error(), "column 'id'")
+
+	err = decodeRow(map[string]interface{}{"created_at": true}, &user)
+	assert.EqualError(t, err, "column 'created_at': cannot decode bool 'true' into time")
+
+	assert.EqualError(t, decodeRow(nil, user), "expect a pointer to a struct, got fixture.fixtureUser")
+}
+
+func (s *SuiteTestFixtureTester) TestFixtureRows() {
+	scope := s.tf.Use("user")
+	defer scope.Clear()
+
+	rows := scope.FixtureRows("user")
+	if assert.Equal(s.T(), 2, len(rows)) {
+		assert.NotContains(s.T(), rows[0], "_label")
+	}
+	assert.Nil(s.T(), scope.FixtureRows("foo"))
+
+	assert.Equal(s.T(), "Beijing, China", scope.FixtureRow("user", "kary")["address"])
+	assert.Equal(s.T(), "Shanghai, China", scope.FixtureRow("user", "2")["address"])
+	assert.PanicsWithValue(s.T(), "fixture row 'bob' not found in table 'user'", func() {
+		scope.FixtureRow("user", "bob")
+	})
+
+	var user queryUser
+	scope.DecodeRow("user", "jack", &user)
+	assert.Equal(s.T(), queryUser{ID: 2, PhoneNo: "+8619303992122", Address: "Shanghai, China"}, user)
+
+	// fixture rows are the same as the inserted ones
+	var users []queryUser
+	scope.ScanInto("user", "id = ?", &users, 1)
+	scope.DecodeRow("user", "kary", &user)
+	assert.Equal(s.T(), users, []queryUser{user})
+
+	child := scope.Use()
+	defer child.Clear()
+	assert.Equal(s.T(), "Beijing, China", child.FixtureRow("user", "kary")["address"])
+}
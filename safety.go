@@ -0,0 +1,128 @@
+package fixture
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+// SafetyConfig guards destructive operations against databases which
+// aren't meant for tests, on top of the 'test_' prefix of the database
+// name. Every refusal is a *SafetyError wrapping one of ErrHostNotAllowed,
+// ErrReadOnlyDatabase, ErrMarkerTableMissing and ErrTooManyRows.
+type SafetyConfig struct {
+	// AllowedHosts lists the database hosts the fixture may connect to,
+	// it defaults to localhost, the loopback addresses and container names
+	// such as 'mysql', which are host names without dots.
+	AllowedHosts []string
+	// MarkerTable must exist in a database before the fixture truncates or
+	// drops any table of it. It's created in the databases the fixture
+	// creates itself. It's checked when the fixture is created and before
+	// it drops tables, scopes clear tables without checking it again.
+	MarkerTable string
+	// MaxRowsToTruncate is the most rows a table may hold for the fixture
+	// to clear or drop it, zero means no limit.
+	MaxRowsToTruncate int64
+}
+
+var defaultAllowedHosts = []string{"", "localhost", "127.0.0.1", "::1"}
+
+// Safety enables the checks of the config, a database server reporting
+// 'read_only' is refused as well, which is checked along with MarkerTable.
+func Safety(c SafetyConfig) Option {
+	return func(tf *TestFixture) {
+		tf.config.Safety = &c
+	}
+}
+
+// checkHost refuses the url unless its host is allowed.
+func (c *SafetyConfig) checkHost(dbURL *DatabaseURL) error {
+	host := dbURL.Hostname()
+	if c.AllowedHosts == nil {
+		if isContainerName(host) {
+			return nil
+		}
+		for _, allowed := range defaultAllowedHosts {
+			if host == allowed {
+				return nil
+			}
+		}
+	} else {
+		for _, allowed := range c.AllowedHosts {
+			if host == allowed {
+				return nil
+			}
+		}
+	}
+
+	return &SafetyError{Err: ErrHostNotAllowed, Detail: fmt.Sprintf("host '%s' of '%s'", host, dbURL.Redacted())}
+}
+
+func isContainerName(host string) bool {
+	for _, c := range host {
+		if c == '.' || c == ':' {
+			return false
+		}
+	}
+	return host != "" && net.ParseIP(host) == nil
+}
+
+// checkDatabase refuses a database on a read only server, or one without
+// the marker table.
+func (tf *TestFixture) checkDatabase(ctx context.Context, dbURL *DatabaseURL) error {
+	db := tf.dbFor(dbURL)
+	ctx, cancel := tf.withTimeout(ctx)
+	defer cancel()
+
+	var readOnly bool
+	query := "SELECT @@global.read_only"
+	if err := db.QueryRowContext(ctx, query).Scan(&readOnly); err != nil {
+		return &OpError{Op: "check", Statement: query, Err: err}
+	}
+	if readOnly {
+		return &SafetyError{Err: ErrReadOnlyDatabase, Detail: fmt.Sprintf("database '%s'", dbNameOf(dbURL))}
+	}
+
+	markerTable := tf.config.Safety.MarkerTable
+	if markerTable == "" {
+		return nil
+	}
+
+	var count int
+	query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?"
+	if err := db.QueryRowContext(ctx, query, markerTable).Scan(&count); err != nil {
+		return &OpError{Op: "check", Statement: query, Err: err}
+	}
+	if count == 0 {
+		return &SafetyError{
+			Err:    ErrMarkerTableMissing,
+			Detail: fmt.Sprintf("table '%s' in database '%s'", markerTable, dbNameOf(dbURL)),
+		}
+	}
+	return nil
+}
+
+// checkRowLimit refuses to clear or drop a table holding more rows than
+// the MaxRowsToTruncate limit.
+func (tf *TestFixture) checkRowLimit(ctx context.Context, db DBTX, tb *table) error {
+	if tf.config.Safety == nil || tf.config.Safety.MaxRowsToTruncate <= 0 {
+		return nil
+	}
+
+	ctx, cancel := tf.withTimeout(ctx)
+	defer cancel()
+
+	var count int64
+	query := "SELECT COUNT(*) FROM " + quoteName(tb.sqlName)
+	if err := db.QueryRowContext(ctx, query).Scan(&count); err != nil {
+		return &OpError{Op: "count", Table: tb.name, Statement: query, Err: err}
+	}
+
+	if count > tf.config.Safety.MaxRowsToTruncate {
+		return &SafetyError{
+			Err:    ErrTooManyRows,
+			Detail: fmt.Sprintf("table '%s' has %d rows, more than %d", tb.name, count, tf.config.Safety.MaxRowsToTruncate),
+		}
+	}
+	return nil
+}
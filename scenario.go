@@ -0,0 +1,192 @@
+package fixture
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+// scenarioManifestName is the name of the manifest in DataDir which
+// defines the scenarios, the leading underscore keeps it from being taken
+// as the fixture file of a table.
+const scenarioManifestName = "_scenarios.yml"
+
+// scenario is a named set of tables to load together, for example:
+//
+//	checkout_with_coupon:
+//	  extends: [checkout]
+//	  tables: [coupon, order]
+//	  files:
+//	    order: order.with_coupon.yml
+//	  sql:
+//	    - UPDATE `order` SET coupon_id = 1
+//
+// Tables come after the ones of the extended scenarios, the files map
+// tables to fixture files relative to DataDir in place of the default
+// ones, and the sql runs after the tables are loaded.
+type scenario struct {
+	Extends []string          `yaml:"extends"`
+	Tables  []string          `yaml:"tables"`
+	Files   map[string]string `yaml:"files"`
+	SQL     []string          `yaml:"sql"`
+}
+
+// UseScenario loads the tables of a scenario defined in the manifest
+// '_scenarios.yml' of DataDir, it panics on any error.
+func (tf *TestFixture) UseScenario(name string) *Scope {
+	scope, err := tf.UseScenarioContext(context.Background(), name)
+	panicOnErr(err)
+	return scope
+}
+
+// UseScenarioContext is like UseScenario but returns the database errors.
+func (tf *TestFixture) UseScenarioContext(ctx context.Context, name string) (*Scope, error) {
+	sc, ok := tf.scenarios[name]
+	if !ok {
+		panic(fmt.Sprintf("scenario '%s' not found", name))
+	}
+	return newScope(ctx, &Scope{tf: tf, files: sc.Files, extraSQL: sc.SQL}, sc.Tables)
+}
+
+// ScenarioNames returns the names of the scenarios in the manifest.
+func (tf *TestFixture) ScenarioNames() []string {
+	names := make([]string, 0, len(tf.scenarios))
+	for name := range tf.scenarios {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// loadScenarios reads the manifest if there is one, and checks every
+// scenario against the tables of the schema.
+func (tf *TestFixture) loadScenarios() error {
+	filename := path.Join(tf.config.FixtureDataDir, scenarioManifestName)
+	if !isPathExist(filename) {
+		return nil
+	}
+
+	buf, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	var manifest map[string]*scenario
+	if err := yaml.Unmarshal(buf, &manifest); err != nil {
+		return fmt.Errorf("failed to load scenarios '%s': %s", filename, err)
+	}
+
+	scenarios, err := tf.resolveScenarios(manifest)
+	if err != nil {
+		return fmt.Errorf("invalid scenarios '%s': %s", filename, err)
+	}
+	tf.scenarios = scenarios
+	return nil
+}
+
+func (tf *TestFixture) resolveScenarios(manifest map[string]*scenario) (map[string]*scenario, error) {
+	scenarios := make(map[string]*scenario, len(manifest))
+	for name := range manifest {
+		sc, err := resolveScenario(manifest, name)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := tf.checkScenario(name, sc); err != nil {
+			return nil, err
+		}
+		scenarios[name] = sc
+	}
+	return scenarios, nil
+}
+
+// resolveScenario flattens a scenario with the ones it extends.
+func resolveScenario(manifest map[string]*scenario, name string) (*scenario, error) {
+	resolved := &scenario{Files: make(map[string]string)}
+	if err := resolved.extend(manifest, name, make(map[string]bool), make(map[string]bool)); err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// extend merges a scenario of the manifest after the ones it extends, a
+// scenario extended along several paths is merged only once, so that its
+// sql doesn't run twice.
+func (sc *scenario) extend(manifest map[string]*scenario, name string, visiting, merged map[string]bool) error {
+	raw, ok := manifest[name]
+	if !ok {
+		return fmt.Errorf("scenario '%s' not found", name)
+	}
+	if merged[name] {
+		return nil
+	}
+	if visiting[name] {
+		return fmt.Errorf("scenario '%s' extends itself", name)
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+
+	if raw != nil {
+		for _, parentName := range raw.Extends {
+			if err := sc.extend(manifest, parentName, visiting, merged); err != nil {
+				return err
+			}
+		}
+		sc.merge(raw)
+	}
+	merged[name] = true
+	return nil
+}
+
+// merge adds the tables, files and sql of other to the scenario, a table
+// selected again replaces the previous one in place.
+func (sc *scenario) merge(other *scenario) {
+	for _, name := range other.Tables {
+		tableName, _ := splitTableVariant(name)
+
+		replaced := false
+		for i, selected := range sc.Tables {
+			if selectedName, _ := splitTableVariant(selected); selectedName == tableName {
+				sc.Tables[i] = name
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			sc.Tables = append(sc.Tables, name)
+		}
+	}
+
+	for tableName, file := range other.Files {
+		sc.Files[tableName] = file
+	}
+	sc.SQL = append(sc.SQL, other.SQL...)
+}
+
+func (tf *TestFixture) checkScenario(name string, sc *scenario) error {
+	selected := make(map[string]bool)
+	for _, tableName := range sc.Tables {
+		tableName, _ = splitTableVariant(tableName)
+		if tf.lookupTable(tableName) == nil {
+			return fmt.Errorf("scenario '%s': table '%s' not found", name, tableName)
+		}
+		selected[tableName] = true
+	}
+
+	for tableName, file := range sc.Files {
+		if !selected[tableName] {
+			return fmt.Errorf("scenario '%s': file '%s' given for table '%s' which is not selected", name, file, tableName)
+		}
+		if _, ok := LookupDataFormatByExt(path.Ext(file)); !ok {
+			return fmt.Errorf("scenario '%s': unknown data format of file '%s'", name, file)
+		}
+		if !isPathExist(path.Join(tf.config.FixtureDataDir, file)) {
+			return fmt.Errorf("scenario '%s': file '%s' not found", name, file)
+		}
+	}
+	return nil
+}
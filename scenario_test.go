@@ -0,0 +1,88 @@
+package fixture
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_resolveScenarios(t *testing.T) {
+	tf := &TestFixture{
+		config: &Config{FixtureDataDir: fixtureDataDir},
+		tables: []*table{{name: "user"}, {name: "foo"}, {name: "bar"}},
+	}
+
+	scenarios, err := tf.resolveScenarios(map[string]*scenario{
+		"base": {Tables: []string{"user", "foo"}, SQL: []string{"SELECT 1"}},
+		"admin": {
+			Extends: []string{"base"},
+			Tables:  []string{"user@admin", "bar"},
+			Files:   map[string]string{"foo": "foo.json"},
+			SQL:     []string{"SELECT 2"},
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &scenario{
+		Tables: []string{"user@admin", "foo", "bar"},
+		Files:  map[string]string{"foo": "foo.json"},
+		SQL:    []string{"SELECT 1", "SELECT 2"},
+	}, scenarios["admin"])
+
+	// the base extended by both parents is merged once
+	scenarios, err = tf.resolveScenarios(map[string]*scenario{
+		"base": {Tables: []string{"foo"}, SQL: []string{"INSERT INTO foo VALUES (9)"}},
+		"a":    {Extends: []string{"base"}, Tables: []string{"user"}},
+		"b":    {Extends: []string{"base"}, Tables: []string{"bar"}, SQL: []string{"SELECT 2"}},
+		"c":    {Extends: []string{"a", "b"}},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &scenario{
+		Tables: []string{"foo", "user", "bar"},
+		Files:  map[string]string{},
+		SQL:    []string{"INSERT INTO foo VALUES (9)", "SELECT 2"},
+	}, scenarios["c"])
+
+	_, err = tf.resolveScenarios(map[string]*scenario{
+		"a": {Extends: []string{"b"}},
+		"b": {Extends: []string{"a"}},
+	})
+	assert.Contains(t, err.Error(), "extends itself")
+
+	_, err = tf.resolveScenarios(map[string]*scenario{"a": {Extends: []string{"missing"}}})
+	assert.EqualError(t, err, "scenario 'missing' not found")
+
+	_, err = tf.resolveScenarios(map[string]*scenario{"a": {Tables: []string{"task"}}})
+	assert.EqualError(t, err, "scenario 'a': table 'task' not found")
+
+	_, err = tf.resolveScenarios(map[string]*scenario{"a": {Files: map[string]string{"user": "user.yml"}}})
+	assert.EqualError(t, err, "scenario 'a': file 'user.yml' given for table 'user' which is not selected")
+
+	_, err = tf.resolveScenarios(map[string]*scenario{
+		"a": {Tables: []string{"user"}, Files: map[string]string{"user": "user.missing.yml"}},
+	})
+	assert.EqualError(t, err, "scenario 'a': file 'user.missing.yml' not found")
+}
+
+func Test_scenarioManifestName(t *testing.T) {
+	// a table named 'scenarios' doesn't load the manifest
+	assert.Nil(t, searchFixtureData(fixtureDataDir, "scenarios", ""))
+}
+
+func (s *SuiteTestFixtureTester) TestUseScenario() {
+	assert.Equal(s.T(), []string{"admin", "base"}, s.tf.ScenarioNames())
+
+	scope := s.tf.UseScenario("admin")
+	assert.Equal(s.T(), 1, countTable(s.db, "user"))
+	assert.Equal(s.T(), 1, scope.Count("user", "address = ?", "Hangzhou, China"))
+	assert.Equal(s.T(), 1, countTable(s.db, "foo"))
+	assert.Equal(s.T(), 2, countTable(s.db, "bar"))
+
+	scope.Clear()
+	for _, name := range []string{"user", "foo", "bar"} {
+		assert.Equal(s.T(), 0, countTable(s.db, name))
+	}
+
+	assert.PanicsWithValue(s.T(), "scenario 'missing' not found", func() {
+		s.tf.UseScenario("missing")
+	})
+}
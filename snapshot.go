@@ -0,0 +1,105 @@
+package fixture
+
+import (
+	"context"
+	"fmt"
+)
+
+// snapshotTablePrefix prefixes the shadow tables holding snapshots.
+const snapshotTablePrefix = "_fx_snap_"
+
+// snapshot is taken by a savepoint in a transactional scope, or else
+// into the shadow tables.
+type snapshot struct {
+	savepoint string
+}
+
+func snapshotTableName(tb *table) string {
+	return snapshotTablePrefix + tb.sqlName
+}
+
+// Snapshot saves the current data of the selected tables so Restore can
+// bring it back, it panics on any error.
+func (s *Scope) Snapshot() {
+	panicOnErr(s.SnapshotContext(context.Background()))
+}
+
+// SnapshotContext is like Snapshot but returns the error. The selected
+// tables are copied into shadow tables, which are dropped by DropTables.
+// A transactional scope takes a savepoint instead.
+func (s *Scope) SnapshotContext(ctx context.Context) error {
+	if s.tx != nil {
+		savepoint := s.nextSavepoint("fixture_snapshot")
+		if _, err := s.tf.exec(ctx, s.tx, "snapshot", "", "SAVEPOINT "+savepoint); err != nil {
+			return err
+		}
+		s.snapshot = &snapshot{savepoint: savepoint}
+		return nil
+	}
+
+	db := s.tf.dbFor(s.url)
+	for _, tb := range s.selectedTables {
+		shadow := snapshotTableName(tb)
+		stmts := []string{
+			"DROP TABLE IF EXISTS " + shadow,
+			fmt.Sprintf("CREATE TABLE %s LIKE %s", shadow, tb.sqlName),
+			fmt.Sprintf("INSERT INTO %s SELECT * FROM %s", shadow, tb.sqlName),
+		}
+		for _, stmt := range stmts {
+			if _, err := s.tf.exec(ctx, db, "snapshot", tb.name, stmt); err != nil {
+				return err
+			}
+		}
+	}
+
+	s.snapshot = &snapshot{}
+	s.tf.logger().Info("snapshot selected tables", "count", len(s.selectedTables))
+	return nil
+}
+
+// Restore brings back the data saved by the last Snapshot, it panics on
+// any error.
+func (s *Scope) Restore() {
+	panicOnErr(s.RestoreContext(context.Background()))
+}
+
+// RestoreContext is like Restore but returns the error.
+func (s *Scope) RestoreContext(ctx context.Context) (err error) {
+	if s.snapshot == nil {
+		return ErrNoSnapshot
+	}
+
+	if s.snapshot.savepoint != "" {
+		_, err := s.tf.exec(ctx, s.tx, "restore", "", "ROLLBACK TO SAVEPOINT "+s.snapshot.savepoint)
+		return err
+	}
+
+	tx, err := s.tf.dbFor(s.url).BeginTx(ctx, nil)
+	if err != nil {
+		return &OpError{Op: "begin", Statement: "BEGIN", Err: err}
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	for _, tb := range s.selectedTables {
+		stmts := []string{
+			"DELETE FROM " + tb.sqlName,
+			fmt.Sprintf("INSERT INTO %s SELECT * FROM %s", tb.sqlName, snapshotTableName(tb)),
+		}
+		for _, stmt := range stmts {
+			if _, err := s.tf.exec(ctx, tx, "restore", tb.name, stmt); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return &OpError{Op: "commit", Statement: "COMMIT", Err: err}
+	}
+
+	s.tf.logger().Info("restore selected tables", "count", len(s.selectedTables))
+	return nil
+}
@@ -0,0 +1,31 @@
+package fixture
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func (s *SuiteTestFixtureTester) TestSnapshotAndRestore() {
+	scope := s.tf.Use("user", "foo")
+	defer scope.Clear()
+
+	assert.Equal(s.T(), ErrNoSnapshot, scope.RestoreContext(context.Background()))
+
+	scope.Snapshot()
+	assert.True(s.T(), isTableExistInDB(s.db, "_fx_snap_user"))
+
+	s.db.Exec("DELETE FROM user WHERE id = 1")
+	s.db.Exec("INSERT INTO foo (id) VALUES (3)")
+	assert.Equal(s.T(), 1, countTable(s.db, "user"))
+	assert.Equal(s.T(), 3, countTable(s.db, "foo"))
+
+	scope.Restore()
+	assert.Equal(s.T(), 2, countTable(s.db, "user"))
+	assert.Equal(s.T(), 2, countTable(s.db, "foo"))
+
+	// A snapshot can be restored again
+	s.db.Exec("DELETE FROM user")
+	scope.Restore()
+	assert.Equal(s.T(), 2, countTable(s.db, "user"))
+}
@@ -0,0 +1,116 @@
+package fixture
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/iFaceless/fixture/loaders"
+)
+
+// ZeroValuePolicy decides how Scope.InsertStructs handles fields of zero
+// value.
+type ZeroValuePolicy int
+
+const (
+	// OmitZeroValues leaves the columns of zero fields out of the insert,
+	// so the database defaults apply, which is the default.
+	OmitZeroValues ZeroValuePolicy = iota
+	// InsertZeroValues inserts zero fields as they are, a nil pointer is
+	// inserted as NULL.
+	InsertZeroValues
+)
+
+func (p ZeroValuePolicy) String() string {
+	switch p {
+	case OmitZeroValues:
+		return "omit"
+	case InsertZeroValues:
+		return "insert"
+	default:
+		return fmt.Sprintf("ZeroValuePolicy(%d)", int(p))
+	}
+}
+
+// tabler is a model naming its table, as the models of gorm do.
+type tabler interface {
+	TableName() string
+}
+
+// InsertStructs inserts structs or struct pointers into the tables named
+// by their TableName method. Columns map to fields the same way as
+// ScanInto, and fields of zero value are handled by the ZeroValues option.
+// Like Insert, the rows are cleared along with the scope. It panics on
+// any error.
+func (s *Scope) InsertStructs(values ...interface{}) *Scope {
+	panicOnErr(s.InsertStructsContext(context.Background(), "", values...))
+	return s
+}
+
+// InsertStructsInto is like InsertStructs but inserts into the given
+// table, the models don't need a TableName method.
+func (s *Scope) InsertStructsInto(tableName string, values ...interface{}) *Scope {
+	panicOnErr(s.InsertStructsContext(context.Background(), tableName, values...))
+	return s
+}
+
+// InsertStructsContext is like InsertStructsInto but returns the database
+// errors, an empty table name takes the ones of the models.
+func (s *Scope) InsertStructsContext(ctx context.Context, tableName string, values ...interface{}) error {
+	for _, value := range values {
+		name := tableName
+		if name == "" {
+			name = structTableName(value)
+		}
+
+		tb := s.tf.lookupTable(name)
+		if tb == nil {
+			panic(fmt.Sprintf("table '%s' not found", name))
+		}
+
+		// zero fields may be omitted, so every struct has its own columns
+		content := &loaders.LoadContent{
+			Table: tb.sqlName,
+			Rows:  []map[string]interface{}{structRow(value, s.tf.config.ZeroValues)},
+		}
+		if _, err := s.insertContent(ctx, tb, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func structTableName(value interface{}) string {
+	if t, ok := value.(tabler); ok {
+		return t.TableName()
+	}
+
+	// TableName may be defined on the pointer while a struct is given
+	v := reflect.New(reflect.TypeOf(value))
+	v.Elem().Set(reflect.ValueOf(value))
+	if t, ok := v.Interface().(tabler); ok {
+		return t.TableName()
+	}
+
+	panic(fmt.Sprintf("fixture: %T has no TableName method, use InsertStructsInto", value))
+}
+
+func structRow(value interface{}, policy ZeroValuePolicy) map[string]interface{} {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("fixture: InsertStructs expects structs, got %T", value))
+	}
+
+	row := make(map[string]interface{})
+	for col, index := range structColumns(v.Type()) {
+		field := v.FieldByIndex(index)
+		if policy == OmitZeroValues && field.IsZero() {
+			continue
+		}
+		row[col] = field.Interface()
+	}
+	return row
+}
@@ -0,0 +1,58 @@
+package fixture
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type structUser struct {
+	ID        int64  `gorm:"column:id;primary_key"`
+	Nickname  int64  `gorm:"column:nickname"`
+	PhoneNo   string `db:"phone_no"`
+	Address   string
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+func (structUser) TableName() string {
+	return "user"
+}
+
+type structTask struct {
+	ID          int64
+	UserID      int64
+	Title       string
+	Description string
+	Checked     bool
+}
+
+func (s *SuiteTestFixtureTester) TestInsertStructs() {
+	scope := s.tf.Use()
+	scope.InsertStructs(&structUser{ID: 1, Nickname: 1, PhoneNo: "+8619300000000"}, structUser{ID: 2, Nickname: 2, Address: "Hangzhou, China"})
+	assert.Equal(s.T(), 2, countTable(s.db, "user"))
+	// zero fields are omitted, so the database defaults apply
+	assert.True(s.T(), scope.Exists("user", "id = 1 AND address = '' AND created_at IS NOT NULL"))
+	assert.True(s.T(), scope.Exists("user", "id = 2 AND address = ?", "Hangzhou, China"))
+
+	scope.InsertStructsInto("task", &structTask{ID: 1, UserID: 1, Title: "Write tests", Description: "-", Checked: true})
+	assert.True(s.T(), scope.Exists("task", "id = 1 AND user_id = 1 AND checked = 1"))
+
+	scope.Clear()
+	assert.Equal(s.T(), 0, countTable(s.db, "user"))
+	assert.Equal(s.T(), 0, countTable(s.db, "task"))
+
+	assert.PanicsWithValue(s.T(), "fixture: *fixture.structTask has no TableName method, use InsertStructsInto", func() {
+		s.tf.Use().InsertStructs(&structTask{ID: 1})
+	})
+}
+
+func (s *SuiteTestFixtureTester) TestInsertStructs_InsertZeroValues() {
+	tf := s.newFixture(ZeroValues(InsertZeroValues))
+
+	scope := tf.Use()
+	defer scope.Clear()
+
+	// a zero time isn't a valid timestamp, so it's set when zero values are inserted
+	scope.InsertStructs(&structUser{ID: 1, CreatedAt: time.Date(2019, 1, 2, 15, 4, 5, 0, time.Local)})
+	assert.True(s.T(), scope.Exists("user", "id = 1 AND nickname = 0 AND phone_no = '' AND YEAR(created_at) = 2019"))
+}
@@ -0,0 +1,54 @@
+package fixture
+
+import (
+	"context"
+	"path"
+	"strings"
+	"testing"
+)
+
+// UseT is like Use but meant for a test. Fixture data is looked up in the
+// directory named after the test under DataDir first, then in the ones of
+// its parent tests, and at last in DataDir itself. For the subtest
+// 'TestCheckout/refund' they are 'TestCheckout/refund/', 'TestCheckout/'
+// and DataDir. The scope is cleared when the test finishes, errors fail
+// the test.
+func (tf *TestFixture) UseT(t testing.TB, tableNames ...string) *Scope {
+	t.Helper()
+	return useT(t, &Scope{tf: tf}, tableNames)
+}
+
+// UseT is like Scope.Use but resolves fixture data like TestFixture.UseT,
+// the child scope is cleared when the test finishes.
+func (s *Scope) UseT(t testing.TB, tableNames ...string) *Scope {
+	t.Helper()
+	return useT(t, &Scope{tf: s.tf, parent: s}, tableNames)
+}
+
+func useT(t testing.TB, scope *Scope, tableNames []string) *Scope {
+	t.Helper()
+
+	scope.testDataDirs = testDataDirs(scope.tf.config.FixtureDataDir, t.Name())
+	scope, err := newScope(context.Background(), scope, tableNames)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if err := scope.ClearContext(context.Background()); err != nil {
+			t.Error(err)
+		}
+	})
+	return scope
+}
+
+// testDataDirs lists the fixture data directories of a test and its parent
+// tests, the innermost first.
+func testDataDirs(dataDir string, testName string) []string {
+	names := strings.Split(testName, "/")
+	dirs := make([]string, 0, len(names))
+	for i := len(names); i > 0; i-- {
+		dirs = append(dirs, path.Join(dataDir, path.Join(names[:i]...)))
+	}
+	return dirs
+}
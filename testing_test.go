@@ -0,0 +1,39 @@
+package fixture
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_testDataDirs(t *testing.T) {
+	assert.Equal(t, []string{
+		path.Join(fixtureDataDir, "TestCheckout", "refund"),
+		path.Join(fixtureDataDir, "TestCheckout"),
+	}, testDataDirs(fixtureDataDir, "TestCheckout/refund"))
+}
+
+func (s *SuiteTestFixtureTester) TestUseT() {
+	s.Run("shared", func() {
+		scope := s.tf.UseT(s.T(), "user", "foo")
+		rows := scope.Rows("user", "")
+		if assert.Equal(s.T(), 1, len(rows)) {
+			assert.Equal(s.T(), "Chengdu, China", rows[0]["address"])
+		}
+		// foo falls back to the shared fixture data
+		assert.Equal(s.T(), 2, countTable(s.db, "foo"))
+	})
+	assert.Equal(s.T(), 0, countTable(s.db, "user"))
+	assert.Equal(s.T(), 0, countTable(s.db, "foo"))
+
+	s.Run("admin", func() {
+		scope := s.tf.UseT(s.T(), "user")
+		assert.True(s.T(), scope.Exists("user", "address = ?", "Hangzhou, China"))
+
+		child := scope.UseT(s.T(), "foo")
+		assert.Equal(s.T(), 2, child.Count("foo", ""))
+	})
+	assert.Equal(s.T(), 0, countTable(s.db, "user"))
+	assert.Equal(s.T(), 0, countTable(s.db, "foo"))
+}